@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+)
+
+// banKeyword marks a chat ID as banned in the bans list
+const banKeyword = "*"
+
+// topUsers is the number of subscribers listed by /users
+const topUsers = 5
+
+// adminCommands are only available to the configured admin chat IDs
+var adminCommands = map[string]bool{
+	"broadcast": true, "users": true, "ban": true, "unban": true,
+	"reload": true, "health": true, "synonyms": true, "unparsed": true,
+}
+
+// banned checks if a chat ID has been banned by an admin
+func (b *Handler) banned(userID int64) bool {
+	return b.bans.Exists(userID, banKeyword)
+}
+
+// users returns every chat ID with something on their watch list
+func (b *Handler) users() []int64 {
+	seen := make(map[int64]bool)
+	for _, d := range b.data {
+		for _, id := range d.GetUsers() {
+			seen[id] = true
+		}
+	}
+	for _, id := range b.follows.GetUsers() {
+		seen[id] = true
+	}
+
+	ids := make([]int64, 0)
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (b *Handler) handleBroadcast(message string) string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "Tell me what to send (e.g. /broadcast The bot restarts tonight)"
+	}
+
+	ids := b.users()
+	sent := 0
+	for _, id := range ids {
+		if b.banned(id) {
+			continue
+		}
+		err := b.chatFor(id).SendMessage(id, message)
+		if err != nil {
+			b.logger.Printf("Unable to broadcast to @%d: %s", id, err)
+			continue
+		}
+		sent++
+	}
+
+	return fmt.Sprintf("Sent your message to <b>%d</b> of <b>%d</b> users", sent, len(ids))
+}
+
+func (b *Handler) handleUsers() string {
+	ids := b.users()
+	watches := make(map[int64]int)
+	total := 0
+	for _, id := range ids {
+		for _, t := range matcher.Types {
+			watches[id] += len(b.data[t].Get(id))
+		}
+		total += watches[id]
+	}
+
+	count := len(ids)
+	sort.SliceStable(ids, func(i, j int) bool { return watches[ids[i]] > watches[ids[j]] })
+	if len(ids) > topUsers {
+		ids = ids[:topUsers]
+	}
+
+	resp := []string{
+		fmt.Sprintf("<b>Users:</b> <i>(%d users, %d watches, %d banned)</i>", count, total, len(b.bans.GetByKeyword(banKeyword))),
+	}
+	for _, id := range ids {
+		resp = append(resp, fmt.Sprintf(" - %d <i>(%d watches)</i>", id, watches[id]))
+	}
+
+	return strings.Join(resp, "\n")
+}
+
+// parseChatID reads the chat ID given to /ban and /unban
+func parseChatID(arg string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+	return id, err == nil
+}
+
+func (b *Handler) handleBan(arg string) string {
+	id, ok := parseChatID(arg)
+	if !ok {
+		return "Tell me which chat ID to ban (e.g. /ban 12345)"
+	}
+	if b.isAdmin(id) {
+		return "Admins can't be banned"
+	}
+	if b.banned(id) {
+		return fmt.Sprintf("<b>%d</b> is already banned", id)
+	}
+
+	err := b.bans.Add(id, banKeyword)
+	if err != nil {
+		b.logger.Println("Unable to add ban: ", err)
+	}
+
+	return fmt.Sprintf("Okay, I'll ignore <b>%d</b> and stop sending them matches", id)
+}
+
+func (b *Handler) handleUnban(arg string) string {
+	id, ok := parseChatID(arg)
+	if !ok {
+		return "Tell me which chat ID to unban (e.g. /unban 12345)"
+	}
+	if !b.banned(id) {
+		return fmt.Sprintf("<b>%d</b> isn't banned", id)
+	}
+
+	err := b.bans.Remove(id, banKeyword)
+	if err != nil {
+		b.logger.Println("Unable to remove ban: ", err)
+	}
+
+	return fmt.Sprintf("Okay, <b>%d</b> is no longer banned", id)
+}
+
+// handleReload reads the synonyms and watch lists from disk again, keeping the
+// current copy of anything that fails to load
+func (b *Handler) handleReload() string {
+	resp := []string{b.handleSynonyms()}
+
+	names := append([]string{}, matcher.Types...)
+	names = append(names, "blocked", "followed", "banned")
+
+	failed := []string{}
+	for _, name := range names {
+		// A missing file is an empty list, anything else keeps the current copy
+		d, err := data.Load(fmt.Sprintf("%s/%s", b.configDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			b.logger.Printf("Unable to reload %s: %v", name, err)
+			failed = append(failed, name)
+			continue
+		}
+
+		switch name {
+		case "blocked":
+			b.blocks = d
+		case "followed":
+			b.follows = d
+		case "banned":
+			b.bans = d
+		default:
+			b.data[name] = d
+		}
+	}
+
+	resp = append(resp, fmt.Sprintf("Reloaded <b>%d</b> of <b>%d</b> lists", len(names)-len(failed), len(names)))
+	if len(failed) > 0 {
+		resp = append(resp, fmt.Sprintf("Kept the current copy of: %s", html.EscapeString(strings.Join(failed, ", "))))
+	}
+	return strings.Join(resp, "\n")
+}
+
+func (b *Handler) handleHealth() string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	lastPost := "never"
+	if !b.lastPost.IsZero() {
+		lastPost = fmt.Sprintf("%s ago", time.Since(b.lastPost).Round(time.Second))
+	}
+
+	return strings.Join([]string{
+		"<b>Health:</b>",
+		fmt.Sprintf(" - version <i>(%s)</i>", html.EscapeString(b.version)),
+		fmt.Sprintf(" - uptime <i>(%s)</i>", time.Since(b.started).Round(time.Second)),
+		fmt.Sprintf(" - last post <i>(%s)</i>", lastPost),
+		fmt.Sprintf(" - goroutines <i>(%d)</i>", runtime.NumGoroutine()),
+		fmt.Sprintf(" - memory <i>(%.1f MB)</i>", float64(mem.Alloc)/1024/1024),
+	}, "\n")
+}
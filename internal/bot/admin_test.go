@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+func TestMessageAdminNotAdmin(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, message := range []string{"/broadcast hi", "/users", "/ban 3", "/unban 4", "/reload", "/health"} {
+		err := obj.incomingMessage(2, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	for _, msg := range actual {
+		expected := "msg/2/That command doesn't look like anything to me."
+		if msg != expected {
+			t.Errorf("Expected %q to equal %q", msg, expected)
+		}
+	}
+	if len(actual) != 6 {
+		t.Errorf("Expected 6 replies, got %q", actual)
+	}
+}
+
+func TestMessageBroadcast(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.data[matcher.Selling] = &mocks.Data{
+		MockGetUsers: func() []int64 {
+			return []int64{2, 3}
+		},
+		MockGet: func(i int64) data.Keywords {
+			if i == 3 {
+				return data.Keywords{"tada68": 1, "tofu": 0}
+			}
+			if i == 2 {
+				return data.Keywords{"kbd67": 0}
+			}
+			return make(data.Keywords)
+		},
+	}
+	obj.follows = &mocks.Data{MockGetUsers: func() []int64 { return []int64{4} }}
+
+	err := obj.incomingMessage(1, "/broadcast Restarting <b>soon</b>")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"msg/2/Restarting <b>soon</b>",
+		"msg/3/Restarting <b>soon</b>",
+		"msg/1/Sent your message to <b>2</b> of <b>3</b> users",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageUsers(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.data[matcher.Selling] = &mocks.Data{
+		MockGetUsers: func() []int64 {
+			return []int64{2, 3}
+		},
+		MockGet: func(i int64) data.Keywords {
+			if i == 3 {
+				return data.Keywords{"tada68": 1, "tofu": 0}
+			}
+			if i == 2 {
+				return data.Keywords{"kbd67": 0}
+			}
+			return make(data.Keywords)
+		},
+	}
+	obj.follows = &mocks.Data{MockGetUsers: func() []int64 { return []int64{4} }}
+
+	err := obj.incomingMessage(1, "/users")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"msg/1/<b>Users:</b> <i>(3 users, 3 watches, 1 banned)</i>\n - 3 <i>(2 watches)</i>\n - 2 <i>(1 watches)</i>\n - 4 <i>(0 watches)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageBan(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, message := range []string{"/ban 3", "/ban 4", "/ban 1", "/ban bob", "/unban 4", "/unban 3"} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"ban/3/*",
+		"msg/1/Okay, I'll ignore <b>3</b> and stop sending them matches",
+		"msg/1/<b>4</b> is already banned",
+		"msg/1/Admins can't be banned",
+		"msg/1/Tell me which chat ID to ban (e.g. /ban 12345)",
+		"unban/4/*",
+		"msg/1/Okay, <b>4</b> is no longer banned",
+		"msg/1/<b>3</b> isn't banned",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestBannedUpdate(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	err := obj.incomingUpdate(tgbotapi.Update{
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 4, Type: "private"}, Text: "/help"},
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	if len(actual) != 0 {
+		t.Errorf("Expected no replies, got %q", actual)
+	}
+}
+
+func TestMessageReload(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.configDir = "/tmp/reload"
+	obj.synonyms = "/tmp/reload/synonyms.json"
+
+	os.RemoveAll("/tmp/reload")
+	os.Mkdir("/tmp/reload", 0755)
+	ioutil.WriteFile("/tmp/reload/synonyms.json", []byte(`{"tada68": ["tada"]}`), 0644)
+	ioutil.WriteFile("/tmp/reload/selling.json", []byte(`{"1": {"kbd67": 3}}`), 0644)
+	ioutil.WriteFile("/tmp/reload/buying.json", []byte(`not json`), 0644)
+	oldBuying := obj.data[matcher.Buying]
+
+	err := obj.incomingMessage(1, "/reload")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		fmt.Sprintf("msg/1/Reloaded synonyms with <b>2</b> terms\nReloaded <b>%d</b> of <b>%d</b> lists\nKept the current copy of: buying", len(matcher.Types)+2, len(matcher.Types)+3),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+	if keywords := obj.data[matcher.Selling].Get(1); keywords["kbd67"] != 3 {
+		t.Errorf("Expected selling to be reloaded, got %+v", keywords)
+	}
+	if obj.data[matcher.Buying] != oldBuying {
+		t.Errorf("Expected buying to be kept")
+	}
+}
+
+func TestMessageHealth(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.version = "v1.2.3"
+
+	err := obj.incomingMessage(1, "/health")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	if len(actual) != 1 || !strings.HasPrefix(actual[0], "msg/1/<b>Health:</b>\n - version <i>(v1.2.3)</i>\n - uptime") {
+		t.Errorf("Expected a health report, got %q", actual)
+	}
+	if !strings.Contains(actual[0], "last post <i>(never)</i>") {
+		t.Errorf("Expected no posts yet, got %q", actual)
+	}
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+)
+
+// username, /u/username, u/username or @username
+var authorRex = regexp.MustCompile(`^(?:/?u/|@)?([\w-]+)$`)
+
+// parseAuthor returns the reddit username from a command argument
+func parseAuthor(arg string) (string, bool) {
+	m := authorRex.FindStringSubmatch(arg)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+func (b *Handler) handleBlock(userID int64, arg string) string {
+	author, ok := parseAuthor(arg)
+	if !ok {
+		return "Which user should I block? (e.g. /block username)"
+	}
+
+	err := b.blocks.Add(userID, author)
+	if err != nil {
+		b.logger.Println("Unable to block author: ", err)
+	}
+
+	return fmt.Sprintf("Okay, I'm going to ignore all posts from <b>/u/%s</b>", html.EscapeString(author))
+}
+
+func (b *Handler) handleUnblock(userID int64, arg string) string {
+	author, ok := parseAuthor(arg)
+	if !ok {
+		return "Which user should I unblock? (e.g. /unblock username)"
+	}
+
+	if !b.blocks.Exists(userID, author) {
+		return fmt.Sprintf("I'm not blocking <b>/u/%s</b>", html.EscapeString(author))
+	}
+
+	err := b.blocks.Remove(userID, author)
+	if err != nil {
+		b.logger.Println("Unable to unblock author: ", err)
+	}
+
+	return fmt.Sprintf("I'm no longer ignoring posts from <b>/u/%s</b>", html.EscapeString(author))
+}
+
+func (b *Handler) handleFollow(userID int64, arg string) string {
+	author, ok := parseAuthor(arg)
+	if !ok {
+		return "Which user should I follow? (e.g. /follow username)"
+	}
+
+	// Following again keeps the hits counted so far
+	if !b.follows.Exists(userID, author) {
+		err := b.follows.Add(userID, author)
+		if err != nil {
+			b.logger.Println("Unable to follow author: ", err)
+		}
+	}
+
+	return fmt.Sprintf("Okay, I'm going to tell you about every post from <b>/u/%s</b>", html.EscapeString(author))
+}
+
+func (b *Handler) handleUnfollow(userID int64, arg string) string {
+	author, ok := parseAuthor(arg)
+	if !ok {
+		return "Which user should I stop following? (e.g. /unfollow username)"
+	}
+
+	if !b.follows.Exists(userID, author) {
+		return fmt.Sprintf("I'm not following <b>/u/%s</b>", html.EscapeString(author))
+	}
+
+	err := b.follows.Remove(userID, author)
+	if err != nil {
+		b.logger.Println("Unable to unfollow author: ", err)
+	}
+
+	return fmt.Sprintf("I'm no longer following posts from <b>/u/%s</b>", html.EscapeString(author))
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestMessageBlock(t *testing.T) {
+	var actual []string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		blocks: &mocks.Data{
+			MockAdd: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("add/%d/%s", i, s))
+				return nil
+			},
+		},
+	}
+
+	for _, msg := range []string{"/block /u/FlakySeller", "/block", "/block two words"} {
+		err := obj.incomingMessage(1, msg)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"add/1/FlakySeller",
+		"msg/1/Okay, I'm going to ignore all posts from <b>/u/FlakySeller</b>",
+		"msg/1/Which user should I block? (e.g. /block username)",
+		"msg/1/Which user should I block? (e.g. /block username)",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageUnblock(t *testing.T) {
+	var actual []string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		blocks: &mocks.Data{
+			MockExists: func(i int64, s string) bool {
+				return s == "flakyseller"
+			},
+			MockRemove: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+				return nil
+			},
+		},
+	}
+
+	for _, msg := range []string{"/unblock @flakyseller", "/unblock someone"} {
+		err := obj.incomingMessage(1, msg)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"rm/1/flakyseller",
+		"msg/1/I'm no longer ignoring posts from <b>/u/flakyseller</b>",
+		"msg/1/I'm not blocking <b>/u/someone</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageFollow(t *testing.T) {
+	var actual []string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		follows: &mocks.Data{
+			MockExists: func(i int64, s string) bool {
+				return s == "vendor"
+			},
+			MockAdd: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("add/%d/%s", i, s))
+				return nil
+			},
+			MockRemove: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+				return nil
+			},
+		},
+	}
+
+	for _, msg := range []string{"/follow u/trusted", "/follow vendor", "/unfollow someone"} {
+		err := obj.incomingMessage(1, msg)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"add/1/trusted",
+		"msg/1/Okay, I'm going to tell you about every post from <b>/u/trusted</b>",
+		"msg/1/Okay, I'm going to tell you about every post from <b>/u/vendor</b>",
+		"msg/1/I'm not following <b>/u/someone</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
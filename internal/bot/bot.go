@@ -3,25 +3,105 @@ package bot
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
 	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/email"
+	"github.com/stjohnjohnson/reddit-watcher/internal/identity"
 	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
 	"github.com/stjohnjohnson/reddit-watcher/internal/scanner"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+	"github.com/stjohnjohnson/reddit-watcher/internal/settings"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
 	"github.com/stjohnjohnson/reddit-watcher/internal/stats"
+	"github.com/stjohnjohnson/reddit-watcher/internal/unparsed"
+	"gopkg.in/telegram-bot-api.v4"
 )
 
+// unparsedLimit is the number of unparsable titles kept on disk
+const unparsedLimit = 500
+
+// Config is the set of options for the bot
+type Config struct {
+	// Token is the Telegram bot token
+	Token string
+	// ConfigDir is the location of user data
+	ConfigDir string
+	// Synonyms is the location of the synonym dictionary
+	Synonyms string
+	// Templates is the directory of the notification style templates
+	Templates string
+	// Admins is the list of chat IDs allowed to run admin commands
+	Admins []int64
+	// Version is the current version of the app
+	Version string
+	// SlackToken is the bot token of the Slack app, Slack is off without it
+	SlackToken string
+	// SlackSecret is the signing secret of the Slack app
+	SlackSecret string
+	// Listen is the address to listen on for Slack requests and unsubscribe links
+	Listen string
+	// SMTPAddr is the SMTP server (host:port) email is sent through, email is off without it
+	SMTPAddr string
+	// SMTPUsername and SMTPPassword log in to the SMTP server, if it needs it
+	SMTPUsername string
+	SMTPPassword string
+	// SMTPFrom is the address email is sent from
+	SMTPFrom string
+	// EmailSecret signs the unsubscribe links in email
+	EmailSecret string
+	// PublicURL is where the Listen address can be reached from the internet
+	PublicURL string
+	// WebhookSecret signs webhook payloads, webhooks are off without it
+	WebhookSecret string
+	// WebhookURL is sent every match, for every user
+	WebhookURL string
+	// MatrixHomeserver is the URL of the bot user's homeserver, Matrix is off without it
+	MatrixHomeserver string
+	// MatrixUser and MatrixPassword log in as the bot user
+	MatrixUser     string
+	MatrixPassword string
+}
+
 // Handler is the bot object
 type Handler struct {
-	version  string
-	data     map[string]data.Interface
-	stats    stats.Interface
-	posts    scanner.Channel
-	scan     scanner.Interface
-	messages chatter.Channel
-	chat     chatter.Interface
-	logger   *log.Logger
+	version      string
+	username     string
+	configDir    string
+	started      time.Time
+	lastPost     time.Time
+	admins       map[int64]bool
+	bans         data.Interface
+	settings     settings.Interface
+	identities   identity.Interface
+	origins      map[int64]notifier.Address
+	notifiers    map[string]notifier.Interface
+	verifySends  map[string]verifySends
+	webhook      string
+	synonyms     string
+	dictionary   *matcher.Dictionary
+	data         map[string]data.Interface
+	blocks       data.Interface
+	follows      data.Interface
+	stats        stats.Interface
+	unparsed     unparsed.Interface
+	jobs         scheduler.Channel
+	schedule     scheduler.Interface
+	posts        scanner.Channel
+	scan         scanner.Interface
+	messages     chatter.Channel
+	chat         chatter.Interface
+	events       slack.Channel
+	slack        slack.Interface
+	unsubscribes email.Channel
+	rooms        matrix.Channel
+	matrix       matrix.Interface
+	logger       *log.Logger
 }
 
 // Loop is the main logic loop, listening for posts or messages from user
@@ -36,33 +116,183 @@ func (b *Handler) Loop() {
 			}
 
 		case update := <-b.messages:
-			// Skip non-messages
-			if update.Message == nil {
-				continue
-			}
-			b.logger.Printf("MSG: %s: %s", update.Message.Chat.UserName, update.Message.Text)
-			err := b.incomingMessage(update.Message.Chat.ID, update.Message.Text)
+			err := b.incomingUpdate(update)
 			if err != nil {
 				b.logger.Printf("message failure: %v", err)
 			}
+
+		case event := <-b.events:
+			b.logger.Printf("SLACK: %s: %s %s", event.Account(), event.Command, event.Text)
+			err := b.incomingSlack(event)
+			if err != nil {
+				b.logger.Printf("slack failure: %v", err)
+			}
+
+		case address := <-b.unsubscribes:
+			b.logger.Printf("UNSUBSCRIBE: %s", address)
+			err := b.incomingUnsubscribe(address)
+			if err != nil {
+				b.logger.Printf("unsubscribe failure: %v", err)
+			}
+
+		case event := <-b.rooms:
+			b.logger.Printf("MATRIX: %s: %s: %s", event.Room, event.Sender, event.Body)
+			err := b.incomingMatrix(event)
+			if err != nil {
+				b.logger.Printf("matrix failure: %v", err)
+			}
+
+		case job := <-b.jobs:
+			b.logger.Printf("JOB: %s for @%d", job.Kind, job.UserID)
+			err := b.incomingJob(job)
+			if err != nil {
+				b.logger.Printf("job failure: %v", err)
+			}
+			err = b.schedule.Done(job)
+			if err != nil {
+				b.logger.Printf("job failure: %v", err)
+			}
+		}
+	}
+}
+
+// incomingUpdate sends a message, file or button press from Telegram to the right handler
+func (b *Handler) incomingUpdate(update tgbotapi.Update) error {
+	// Button presses carry their own data
+	if query := update.CallbackQuery; query != nil && query.Message != nil {
+		chat := query.Message.Chat
+		b.logger.Printf("CALLBACK: %s: %s", newSender(query.From).Name, query.Data)
+		if b.ignored(chat, query.From) {
+			return nil
+		}
+		if isGroup(chat) {
+			return b.incomingGroupCallback(chat.ID, newSender(query.From), query.Message.MessageID, query.ID, query.Data)
+		}
+		return b.incomingCallback(chat.ID, b.telegramUser(chat.ID), query.Message.MessageID, query.ID, query.Data)
+	}
+
+	// Skip non-messages
+	message := update.Message
+	if message == nil || message.Chat == nil || b.ignored(message.Chat, message.From) {
+		return nil
+	}
+
+	// Files are commands in the caption
+	if message.Document != nil {
+		b.logger.Printf("DOC: %s: %s", message.Chat.UserName, message.Caption)
+		if isGroup(message.Chat) {
+			return b.incomingGroupDocument(message.Chat.ID, newSender(message.From), message.Caption, message.Document.FileID)
 		}
+		return b.incomingDocument(b.telegramUser(message.Chat.ID), message.Caption, message.Document.FileID)
+	}
+
+	if isGroup(message.Chat) {
+		b.logger.Printf("GROUP: %s: %s: %s", message.Chat.Title, newSender(message.From).Name, message.Text)
+		return b.incomingGroupMessage(message.Chat.ID, newSender(message.From), message.Text)
+	}
+
+	b.logger.Printf("MSG: %s: %s", message.Chat.UserName, message.Text)
+	return b.incomingMessage(b.telegramUser(message.Chat.ID), message.Text)
+}
+
+// ignored checks if a chat or the member who sent a message has been banned
+func (b *Handler) ignored(chat *tgbotapi.Chat, from *tgbotapi.User) bool {
+	if b.banned(chat.ID) {
+		return true
+	}
+	return from != nil && b.banned(int64(from.ID))
+}
+
+// isAdmin checks if the chat ID is allowed to run admin commands
+func (b *Handler) isAdmin(userID int64) bool {
+	return b.admins[userID]
+}
+
+// listen serves requests from Slack and email links in the background
+func listen(addr string, handler http.Handler, logger *log.Logger) {
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
 	}
+
+	go func() {
+		err := server.ListenAndServe()
+		logger.Printf("Stopped listening: %v", err)
+	}()
+	logger.Printf("Listening for requests on %s", addr)
 }
 
 // New creates a new bot given a Telegram token and config directory
-func New(token, configDir, version string) (*Handler, error) {
+func New(config Config) (*Handler, error) {
 	appData := make(map[string]data.Interface)
 	logger := log.New(os.Stderr, "[BOT]  ", log.LstdFlags)
 
 	for _, t := range matcher.Types {
-		d, err := data.Load(fmt.Sprintf("%s/%s", configDir, t))
+		d, err := data.Load(fmt.Sprintf("%s/%s", config.ConfigDir, t))
 		if err != nil {
 			logger.Printf("Unable to load config: %v", err)
 		}
 		appData[t] = d
 	}
 
-	scan, err := scanner.New(version)
+	blocks, err := data.Load(fmt.Sprintf("%s/blocked", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load blocked authors: %v", err)
+	}
+
+	follows, err := data.Load(fmt.Sprintf("%s/followed", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load followed authors: %v", err)
+	}
+
+	bans, err := data.Load(fmt.Sprintf("%s/banned", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load banned users: %v", err)
+	}
+
+	prefs, err := settings.Load(fmt.Sprintf("%s/settings", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load settings: %v", err)
+	}
+
+	identities, err := identity.Load(fmt.Sprintf("%s/identities", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load identities: %v", err)
+	}
+
+	dictionary, err := matcher.LoadDictionary(config.Synonyms)
+	if err != nil {
+		logger.Printf("Unable to load synonyms: %v", err)
+	}
+
+	err = notifier.LoadTemplates(config.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load templates: %v", err)
+	}
+
+	corpus, err := unparsed.Load(fmt.Sprintf("%s/unparsed", config.ConfigDir), unparsedLimit)
+	if err != nil {
+		logger.Printf("Unable to load unparsed titles: %v", err)
+	}
+
+	schedule, err := scheduler.Load(fmt.Sprintf("%s/jobs", config.ConfigDir))
+	if err != nil {
+		logger.Printf("Unable to load jobs: %v", err)
+	}
+
+	jobs, err := schedule.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start scheduler: %v", err)
+	}
+
+	admins := make(map[int64]bool)
+	for _, id := range config.Admins {
+		admins[id] = true
+	}
+
+	scan, err := scanner.New(config.Version)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to setup scanner: %v", err)
 	}
@@ -72,24 +302,119 @@ func New(token, configDir, version string) (*Handler, error) {
 		return nil, fmt.Errorf("Failed to start scanner: %v", err)
 	}
 
-	chat, err := chatter.New(version, token)
+	chat, err := chatter.New(config.Version, config.Token)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to setup chatter: %v", err)
 	}
 
+	username := chat.Username()
+
 	messages, err := chat.Start()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to start chatter: %v", err)
 	}
 
-	return &Handler{
-		version:  version,
-		data:     appData,
-		stats:    stats.New(),
-		posts:    posts,
-		scan:     scan,
-		messages: messages,
-		chat:     chat,
-		logger:   logger,
-	}, nil
+	notifiers := map[string]notifier.Interface{
+		notifier.Discord: notifier.NewDiscord(),
+		notifier.Ntfy:    notifier.NewNtfy(),
+		notifier.Gotify:  notifier.NewGotify(),
+	}
+
+	// Slack and email are optional, a nil channel never receives events
+	mux := http.NewServeMux()
+	var app *slack.Handler
+	var events slack.Channel
+	if config.SlackToken != "" {
+		app, err = slack.New(config.SlackToken, config.SlackSecret)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to setup slack: %v", err)
+		}
+
+		events, err = app.Start()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to start slack: %v", err)
+		}
+		notifiers[notifier.Slack] = notifier.NewSlack(app)
+		mux.Handle("/slack/", app)
+	}
+
+	var unsubscribes email.Channel
+	if config.SMTPAddr != "" {
+		mailer, err := email.New(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.SMTPFrom, config.EmailSecret, config.PublicURL)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to setup email: %v", err)
+		}
+
+		unsubscribes, err = mailer.Start()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to start email: %v", err)
+		}
+		notifiers[notifier.Email] = notifier.NewEmail(mailer)
+		mux.Handle("/email/", mailer)
+	}
+
+	var matrixBot *matrix.Handler
+	var rooms matrix.Channel
+	if config.MatrixHomeserver != "" {
+		matrixBot, err = matrix.New(config.MatrixHomeserver, config.MatrixUser, config.MatrixPassword)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to setup matrix: %v", err)
+		}
+
+		rooms, err = matrixBot.Start()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to start matrix: %v", err)
+		}
+		notifiers[notifier.Matrix] = notifier.NewMatrix(matrixBot)
+	}
+
+	if config.WebhookSecret != "" {
+		notifiers[notifier.Webhook] = notifier.NewWebhook(config.WebhookSecret, config.WebhookURL, fmt.Sprintf("%s/deadletter.jsonl", config.ConfigDir))
+	} else if config.WebhookURL != "" {
+		return nil, fmt.Errorf("Failed to setup webhook: a secret is required to sign payloads")
+	}
+
+	if app != nil || unsubscribes != nil {
+		listen(config.Listen, mux, logger)
+	}
+
+	handler := &Handler{
+		version:      config.Version,
+		username:     username,
+		configDir:    config.ConfigDir,
+		started:      time.Now(),
+		admins:       admins,
+		bans:         bans,
+		settings:     prefs,
+		identities:   identities,
+		notifiers:    notifiers,
+		webhook:      config.WebhookURL,
+		synonyms:     config.Synonyms,
+		dictionary:   dictionary,
+		data:         appData,
+		blocks:       blocks,
+		follows:      follows,
+		stats:        stats.New(),
+		unparsed:     corpus,
+		jobs:         jobs,
+		schedule:     schedule,
+		posts:        posts,
+		scan:         scan,
+		messages:     messages,
+		chat:         chat,
+		events:       events,
+		unsubscribes: unsubscribes,
+		rooms:        rooms,
+		logger:       logger,
+	}
+	// Keep the interfaces nil when Slack or Matrix are off
+	if app != nil {
+		handler.slack = app
+	}
+	if matrixBot != nil {
+		handler.matrix = matrixBot
+	}
+	handler.migrateTelegram()
+
+	return handler, nil
 }
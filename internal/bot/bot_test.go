@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/identity"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+const (
+	// slackID is the user ID of the Slack account T1/U1
+	slackID = identity.Offset + 1
+	// matrixID is the user ID of the Matrix room !room:example.com
+	matrixID = slackID + 10
+)
+
+// testHandler returns a bot with every dependency mocked, adding each call that
+// changes something to actual, tests replace the mocks they need to
+func testHandler(actual *[]string) *Handler {
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				return make(data.Keywords)
+			},
+		}
+	}
+	d[matcher.Selling] = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"tada68": 1}
+		},
+		MockExists: func(int64, string) bool {
+			return false
+		},
+		MockAdd: func(i int64, s string) error {
+			*actual = append(*actual, fmt.Sprintf("add/%d/%s", i, s))
+			return nil
+		},
+		MockRemove: func(i int64, s string) error {
+			*actual = append(*actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+
+	return &Handler{
+		username: "MechKeyBot",
+		admins:   map[int64]bool{1: true},
+		logger:   log.New(ioutil.Discard, "", 0),
+		identities: &mocks.Identity{
+			MockResolve: func(transport, account string) (int64, error) {
+				switch {
+				case transport == notifier.Matrix:
+					return matrixID, nil
+				case account == "T1/U1":
+					return slackID, nil
+				}
+				return slackID + 1, nil
+			},
+			MockLookup: func(i int64) (string, string, bool) {
+				switch i {
+				case slackID:
+					return notifier.Slack, "T1/U1", true
+				case matrixID:
+					return notifier.Matrix, "!room:example.com", true
+				}
+				return "", "", false
+			},
+			MockNewCode: func(i int64) (string, error) {
+				return fmt.Sprintf("0000000%d", i), nil
+			},
+			MockRedeem: func(c string, i int64) (int64, bool, error) {
+				switch c {
+				case "00000001":
+					return 1, true, nil
+				case "00000002":
+					return i, true, nil
+				}
+				return 0, false, nil
+			},
+		},
+		chat: &mocks.Chatter{
+			MockIsChatAdmin: func(i int64, u int) (bool, error) {
+				return u == 7, nil
+			},
+			MockSendMessage: func(i int64, s string) error {
+				*actual = append(*actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+			MockEditMessage: func(i int64, n int, s string) error {
+				*actual = append(*actual, fmt.Sprintf("edit/%d/%d/%s", i, n, s))
+				return nil
+			},
+			MockSendKeyboard: func(i int64, s string, rows [][]chatter.Button) error {
+				*actual = append(*actual, fmt.Sprintf("keyboard/%d/%s/%v", i, s, rows))
+				return nil
+			},
+			MockEditKeyboard: func(i int64, n int, s string, rows [][]chatter.Button) error {
+				*actual = append(*actual, fmt.Sprintf("editkeyboard/%d/%d/%s/%v", i, n, s, rows))
+				return nil
+			},
+			MockAnswerCallback: func(id, s string) error {
+				*actual = append(*actual, fmt.Sprintf("answer/%s/%s", id, s))
+				return nil
+			},
+		},
+		data:   d,
+		blocks: &mocks.Data{},
+		bans: &mocks.Data{
+			MockExists: func(i int64, s string) bool {
+				return i == 4 || i == slackID+1
+			},
+			MockGetByKeyword: func(string) []int64 {
+				return []int64{4}
+			},
+			MockAdd: func(i int64, s string) error {
+				*actual = append(*actual, fmt.Sprintf("ban/%d/%s", i, s))
+				return nil
+			},
+			MockRemove: func(i int64, s string) error {
+				*actual = append(*actual, fmt.Sprintf("unban/%d/%s", i, s))
+				return nil
+			},
+		},
+		slack: &mocks.Slack{
+			MockPostMessage: func(c, s string, blocks []slack.Block) error {
+				*actual = append(*actual, fmt.Sprintf("post/%s/%s/%d", c, s, len(blocks)))
+				return nil
+			},
+			MockRespond: func(u, s string, blocks []slack.Block, replace bool) error {
+				*actual = append(*actual, fmt.Sprintf("respond/%s/%s/%t", u, s, replace))
+				return nil
+			},
+		},
+		matrix: &mocks.Matrix{
+			MockSendMessage: func(r, text, html string) error {
+				*actual = append(*actual, fmt.Sprintf("matrix/%s/%s", r, text))
+				return nil
+			},
+			MockIsRoomAdmin: func(r, u string) (bool, error) {
+				return u == "@alice:example.com", nil
+			},
+		},
+		settings: testSettings(actual, make(map[string]string)),
+		notifiers: map[string]notifier.Interface{
+			notifier.Discord: &mocks.Notifier{
+				MockValidate: func(s string) error {
+					if s != "https://discord.com/api/webhooks/1/abc" {
+						return fmt.Errorf("not a Discord webhook URL")
+					}
+					return nil
+				},
+				MockNotify: func(s string, n notifier.Notification) error {
+					*actual = append(*actual, fmt.Sprintf("discord/%s/%s", s, n.Title))
+					return nil
+				},
+			},
+			notifier.Webhook: &mocks.Signer{
+				MockSigningKey: func(s string) string {
+					return "key-for-" + s
+				},
+			},
+			notifier.Email: &mocks.Verifier{
+				MockVerify: func(s, code string) error {
+					*actual = append(*actual, fmt.Sprintf("verify/%s", s))
+					return nil
+				},
+			},
+		},
+		follows:  &mocks.Data{},
+		schedule: &mocks.Scheduler{},
+	}
+}
+
+// testSettings returns settings kept in values, adding each change to actual
+func testSettings(actual *[]string, values map[string]string) *mocks.Settings {
+	return &mocks.Settings{
+		MockGet: func(i int64, k string) string {
+			return values[k]
+		},
+		MockSet: func(i int64, k, v string) error {
+			*actual = append(*actual, fmt.Sprintf("set/%d/%s/%s", i, k, v))
+			if v == "" {
+				delete(values, k)
+			} else {
+				values[k] = v
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+)
+
+// incomingCallback handles a button press, replacing the buttons with the outcome
+// The chat is only the user ID when it hasn't been linked to another account
+func (b *Handler) incomingCallback(chatID, userID int64, messageID int, callbackID, data string) error {
+	if isItems(data) {
+		text, rows := b.runItems(userID, data)
+		return b.answerKeyboard(chatID, messageID, callbackID, text, rows)
+	}
+	return b.answer(chatID, messageID, callbackID, b.runCallback(userID, data))
+}
+
+// answer acknowledges a button press and replaces the buttons with the response
+func (b *Handler) answer(chatID int64, messageID int, callbackID, resp string) error {
+	err := b.chat.AnswerCallback(callbackID, "")
+	if err != nil {
+		b.logger.Printf("Unable to answer callback: %s", err)
+	}
+
+	err = b.chat.EditMessage(chatID, messageID, resp)
+	if err != nil {
+		return fmt.Errorf("Unable to edit message: %v", err)
+	}
+
+	return nil
+}
+
+// answerKeyboard acknowledges a button press and replaces the message and its buttons
+func (b *Handler) answerKeyboard(chatID int64, messageID int, callbackID, text string, rows [][]chatter.Button) error {
+	err := b.chat.AnswerCallback(callbackID, "")
+	if err != nil {
+		b.logger.Printf("Unable to answer callback: %s", err)
+	}
+
+	err = b.chat.EditKeyboard(chatID, messageID, text, rows)
+	if err != nil {
+		return fmt.Errorf("Unable to edit message: %v", err)
+	}
+
+	return nil
+}
+
+// runCallback executes the action of a button and returns the response
+func (b *Handler) runCallback(userID int64, data string) string {
+	var resp string
+	action := strings.SplitN(data, " ", 2)
+	switch action[0] {
+	case "clear":
+		arg := ""
+		if len(action) > 1 {
+			arg = action[1]
+		}
+		resp = b.confirmClear(userID, arg)
+
+	case "cancel":
+		resp = "Okay, I've left your watch list alone"
+
+	default:
+		resp = "That button doesn't do anything anymore"
+	}
+
+	return resp
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestCallbackClear(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				return make(data.Keywords)
+			},
+		}
+	}
+	d[matcher.Selling] = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"tada68": 2}
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockEditMessage: func(i int64, n int, s string) error {
+				actual = append(actual, fmt.Sprintf("edit/%d/%d/%s", i, n, s))
+				return nil
+			},
+			MockAnswerCallback: func(id, s string) error {
+				actual = append(actual, fmt.Sprintf("answer/%s/%s", id, s))
+				return nil
+			},
+		},
+		data:     d,
+		schedule: &mocks.Scheduler{},
+	}
+
+	for _, data := range []string{"clear all", "cancel", "bogus"} {
+		err := obj.incomingCallback(1, 1, 5, "cb", data)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"rm/1/tada68",
+		"answer/cb/",
+		"edit/1/5/Okay, I'm no longer watching for <b>1</b> items",
+		"answer/cb/",
+		"edit/1/5/Okay, I've left your watch list alone",
+		"answer/cb/",
+		"edit/1/5/That button doesn't do anything anymore",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+)
+
+// expireJob removes a subscription once its time is up
+const expireJob = "expire"
+
+// scheduleExpiry sets up the removal of a subscription with a for: option
+// It returns a description of when the subscription ends
+func (b *Handler) scheduleExpiry(userID int64, cmd, keyword string) string {
+	sub := matcher.ParseSubscription(keyword)
+	ends := []string{}
+
+	if sub.For != "" {
+		duration, err := scheduler.ParseDuration(sub.For)
+		if err != nil {
+			b.logger.Println("Unable to parse expiry: ", err)
+		} else {
+			job := scheduler.Job{
+				Kind:    expireJob,
+				UserID:  userID,
+				Type:    cmd,
+				Keyword: strings.ToLower(keyword),
+				At:      time.Now().Add(duration),
+			}
+			err = b.schedule.Add(job)
+			if err != nil {
+				b.logger.Println("Unable to schedule expiry: ", err)
+			}
+			ends = append(ends, fmt.Sprintf("until <b>%s</b>", job.At.UTC().Format(timeFormat)))
+		}
+	}
+
+	if sub.Once {
+		ends = append(ends, "until the first match")
+	}
+
+	if len(ends) == 0 {
+		return ""
+	}
+	return " " + strings.Join(ends, " or ")
+}
+
+// clearJobs cancels anything scheduled for a subscription
+func (b *Handler) clearJobs(userID int64, cmd, keyword string) {
+	for _, kind := range []string{snoozeJob, expireJob} {
+		err := b.schedule.Remove(scheduler.Job{Kind: kind, UserID: userID, Type: cmd, Keyword: strings.ToLower(keyword)})
+		if err != nil {
+			b.logger.Println("Unable to remove job: ", err)
+		}
+	}
+}
+
+// endWatch removes a subscription and tells the user why
+func (b *Handler) endWatch(userID int64, cmd, keyword, reason string) error {
+	d, ok := b.data[cmd]
+	if !ok || !d.Exists(userID, keyword) {
+		return nil
+	}
+
+	err := d.Remove(userID, keyword)
+	if err != nil {
+		b.logger.Println("Unable to remove keyword: ", err)
+	}
+	b.clearJobs(userID, cmd, keyword)
+
+	resp := fmt.Sprintf("%s, so I'm no longer watching for <b>%s</b> posts that match <b>%s</b>", reason, html.EscapeString(cmd), html.EscapeString(keyword))
+	err = b.chatFor(userID).SendMessage(userID, resp)
+	if err != nil {
+		return fmt.Errorf("Unable to send message: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMessageSubscribeFor(t *testing.T) {
+	var actual []string
+	var jobs []scheduler.Job
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		schedule: &mocks.Scheduler{
+			MockAdd: func(j scheduler.Job) error {
+				jobs = append(jobs, j)
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	err := obj.incomingMessage(1, "/selling Tada68 for:14d once")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	if len(jobs) != 1 || jobs[0].Kind != expireJob || jobs[0].Keyword != "tada68 for:14d once" {
+		t.Fatalf("Expected an expiry job, got %+v", jobs)
+	}
+	if d := time.Until(jobs[0].At); d < 13*24*time.Hour || d > 14*24*time.Hour {
+		t.Errorf("Expected expiry in 14 days, got %v", d)
+	}
+	expected := "Okay, I'm going to watch for <b>selling</b> posts that match <b>Tada68 for:14d once</b> until <b>"
+	if len(actual) != 1 || !strings.HasPrefix(actual[0], expected) || !strings.HasSuffix(actual[0], "</b> or until the first match") {
+		t.Errorf("Expected %q to start with %q", actual, expected)
+	}
+}
+
+func TestMessageUnsubscribeClearsJobs(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{
+		MockExists: func(int64, string) bool {
+			return true
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat:   &mocks.Chatter{},
+		schedule: &mocks.Scheduler{
+			MockRemove: func(j scheduler.Job) error {
+				actual = append(actual, fmt.Sprintf("%s/%d/%s/%s", j.Kind, j.UserID, j.Type, j.Keyword))
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	obj.incomingMessage(1, "/selling Tada68 for:14d")
+
+	expected := []string{
+		"snooze/1/selling/tada68 for:14d",
+		"expire/1/selling/tada68 for:14d",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestJobExpire(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "tada68 for:14d"
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		schedule: &mocks.Scheduler{},
+		data:     d,
+	}
+
+	for _, keyword := range []string{"tada68 for:14d", "removed for:1d"} {
+		err := obj.incomingJob(scheduler.Job{Kind: expireJob, UserID: 1, Type: matcher.Selling, Keyword: keyword})
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"rm/1/tada68 for:14d",
+		"msg/1/Time's up, so I'm no longer watching for <b>selling</b> posts that match <b>tada68 for:14d</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestHitOnce(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68 once"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+		MockExists: func(i int64, s string) bool {
+			return true
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		Permalink: "/r/foo",
+		URL:       "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"msg/1/[US-CA] [H] <b>Tada68</b> [W] PayPal [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling tada68 once)</i>",
+		"rm/1/tada68 once",
+		"msg/1/That was your first match, so I'm no longer watching for <b>selling</b> posts that match <b>tada68 once</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
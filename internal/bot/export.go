@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+)
+
+// exportVersion is the current version of the export file format
+const exportVersion = 1
+
+// maxKeywordLength is the longest keyword that can be imported
+const maxKeywordLength = 200
+
+var importText = `To import a watch list, send me the file from /export with one of these captions:
+ /import merge - adds the watches to your current list
+ /import replace - replaces your current list with the watches`
+
+// exportFile is the document sent by /export and read by /import
+type exportFile struct {
+	Version       int                      `json:"version"`
+	Subscriptions map[string][]interface{} `json:"subscriptions"`
+}
+
+func (b *Handler) handleExport(userID int64) string {
+	export := exportFile{
+		Version:       exportVersion,
+		Subscriptions: make(map[string][]interface{}),
+	}
+
+	total := 0
+	for _, t := range matcher.Types {
+		keys := make([]string, 0)
+		for k := range b.data[t].Get(userID) {
+			keys = append(keys, k)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			export.Subscriptions[t] = append(export.Subscriptions[t], k)
+		}
+		total += len(keys)
+	}
+
+	if total == 0 {
+		return "There are no items on your watch list"
+	}
+
+	contents, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		b.logger.Println("Unable to export: ", err)
+		return "Sorry, I wasn't able to export your watch list"
+	}
+
+	caption := fmt.Sprintf("Your watch list with %d items, send it back with the caption /import merge or /import replace", total)
+	err = b.chatFor(userID).SendDocument(userID, "watchlist.json", contents, caption)
+	if err != nil {
+		b.logger.Println("Unable to send export: ", err)
+		return "Sorry, I wasn't able to send your watch list"
+	}
+
+	return ""
+}
+
+// incomingDocument handles files sent by the user, using the caption as the command
+func (b *Handler) incomingDocument(userID int64, caption, fileID string) error {
+	cmd, args, ok := b.parseCommand(caption)
+	if !ok || strings.ToLower(cmd) != "import" {
+		return nil
+	}
+
+	return b.reply(userID, b.handleImport(userID, strings.ToLower(strings.TrimSpace(args)), fileID))
+}
+
+func (b *Handler) handleImport(userID int64, mode, fileID string) string {
+	if mode != "merge" && mode != "replace" {
+		return importText
+	}
+
+	contents, err := b.chat.GetFile(fileID)
+	if err != nil {
+		b.logger.Println("Unable to download import: ", err)
+		return "Sorry, I wasn't able to download that file"
+	}
+
+	var export exportFile
+	err = json.Unmarshal(contents, &export)
+	if err != nil || export.Version != exportVersion {
+		return "That doesn't look like a file from /export"
+	}
+
+	// Check everything before changing anything
+	valid := make(map[string][]string)
+	invalid := []string{}
+	types := make([]string, 0)
+	for t := range export.Subscriptions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		if _, ok := b.data[t]; !ok {
+			invalid = append(invalid, fmt.Sprintf(" - <b>%s</b>: unknown type", html.EscapeString(t)))
+			continue
+		}
+		for _, entry := range export.Subscriptions[t] {
+			keyword, ok := entry.(string)
+			keyword = strings.TrimSpace(keyword)
+			switch {
+			case !ok:
+				invalid = append(invalid, fmt.Sprintf(" - <b>%s</b>: %v is not a keyword", t, html.EscapeString(fmt.Sprint(entry))))
+			case keyword == "":
+				invalid = append(invalid, fmt.Sprintf(" - <b>%s</b>: empty keyword", t))
+			case len(keyword) > maxKeywordLength:
+				invalid = append(invalid, fmt.Sprintf(" - <b>%s</b>: keyword is too long", t))
+			default:
+				valid[t] = append(valid[t], keyword)
+			}
+		}
+	}
+
+	if mode == "replace" {
+		for _, t := range matcher.Types {
+			for keyword := range b.data[t].Get(userID) {
+				err = b.data[t].Remove(userID, keyword)
+				if err != nil {
+					b.logger.Println("Unable to remove keyword: ", err)
+				}
+				b.clearJobs(userID, t, keyword)
+			}
+		}
+	}
+
+	added, existing := 0, 0
+	for t, keywords := range valid {
+		for _, keyword := range keywords {
+			if b.data[t].Exists(userID, keyword) {
+				existing++
+				continue
+			}
+
+			err = b.data[t].Add(userID, keyword)
+			if err != nil {
+				b.logger.Println("Unable to add keyword: ", err)
+			}
+			b.scheduleExpiry(userID, t, keyword)
+			added++
+		}
+	}
+
+	resp := []string{
+		fmt.Sprintf("Okay, I've imported <b>%d</b> new items to your watch list (%d were already there)", added, existing),
+	}
+	if len(invalid) > 0 {
+		resp = append(resp, "", "These entries were invalid:")
+		resp = append(resp, invalid...)
+	}
+	return strings.Join(resp, "\n")
+}
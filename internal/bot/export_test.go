@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestMessageExport(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				return make(data.Keywords)
+			},
+		}
+	}
+	d[matcher.Selling] = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"tada68": 2, "gmk olivia once": 0}
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+			MockSendDocument: func(i int64, n string, c []byte, s string) error {
+				actual = append(actual, fmt.Sprintf("doc/%d/%s/%s/%s", i, n, c, s))
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	err := obj.incomingMessage(1, "/export")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"doc/1/watchlist.json/{\n  \"version\": 1,\n  \"subscriptions\": {\n    \"selling\": [\n      \"gmk olivia once\",\n      \"tada68\"\n    ]\n  }\n}/Your watch list with 2 items, send it back with the caption /import merge or /import replace",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageExportEmpty(t *testing.T) {
+	var actual string
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				return make(data.Keywords)
+			},
+		}
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = s
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	obj.incomingMessage(1, "/export")
+
+	expected := "There are no items on your watch list"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestDocumentImport(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		typ := t
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				if typ == matcher.Buying {
+					return data.Keywords{"hhkb": 1}
+				}
+				return make(data.Keywords)
+			},
+			MockExists: func(i int64, s string) bool {
+				return s == "tada68"
+			},
+			MockAdd: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("add/%s/%s", typ, s))
+				return nil
+			},
+			MockRemove: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("rm/%s/%s", typ, s))
+				return nil
+			},
+		}
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+			MockGetFile: func(s string) ([]byte, error) {
+				return []byte(`{"version": 1, "subscriptions": {"selling": ["tada68", "kbd67", "", 42], "bartering": ["foo"]}}`), nil
+			},
+		},
+		schedule: &mocks.Scheduler{},
+		data:     d,
+	}
+
+	err := obj.incomingDocument(1, "/import replace", "file")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"rm/buying/hhkb",
+		"add/selling/kbd67",
+		"msg/1/Okay, I've imported <b>1</b> new items to your watch list (1 were already there)\n\nThese entries were invalid:\n - <b>bartering</b>: unknown type\n - <b>selling</b>: empty keyword\n - <b>selling</b>: 42 is not a keyword",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestDocumentImportBad(t *testing.T) {
+	var actual []string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+			MockGetFile: func(s string) ([]byte, error) {
+				if s == "missing" {
+					return nil, fmt.Errorf("not found")
+				}
+				return []byte(`not json`), nil
+			},
+		},
+	}
+
+	for _, caption := range []string{"/import merge", "/import", "/import merge", "just a file"} {
+		fileID := "file"
+		if len(actual) == 2 {
+			fileID = "missing"
+		}
+		err := obj.incomingDocument(1, caption, fileID)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"That doesn't look like a file from /export",
+		importText,
+		"Sorry, I wasn't able to download that file",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+// sender is the member of a group chat that sent a message
+type sender struct {
+	ID   int
+	Name string
+}
+
+// newSender describes a Telegram user, preferring their username
+func newSender(user *tgbotapi.User) sender {
+	if user == nil {
+		return sender{}
+	}
+	name := user.FirstName
+	if user.UserName != "" {
+		name = "@" + user.UserName
+	}
+	return sender{ID: user.ID, Name: name}
+}
+
+// isGroup checks if a chat is shared by several members
+func isGroup(chat *tgbotapi.Chat) bool {
+	return chat.IsGroup() || chat.IsSuperGroup()
+}
+
+// changeCommands are the commands that modify a chat's watch list
+// Linking is refused in groups, but it would hand a Matrix room's watch list to another account
+var changeCommands = map[string]bool{
+	"watch": true, "unwatch": true, "clear": true,
+	"block": true, "unblock": true, "follow": true, "unfollow": true,
+	"pause": true, "resume": true, "snooze": true, "import": true, "notify": true,
+	"link": true, "style": true, "app": true, "rich": true,
+}
+
+var groupAdminText = "Sorry, only the admins of this chat can change its watch list"
+
+// isChange checks if a command modifies a chat's watch list
+func isChange(cmd string) bool {
+	cmd = strings.ToLower(cmd)
+	for _, t := range matcher.Types {
+		if cmd == t {
+			return true
+		}
+	}
+	return changeCommands[cmd]
+}
+
+// isGroupAdmin checks if the sender is allowed to change the group's watch list
+func (b *Handler) isGroupAdmin(chatID int64, from sender) bool {
+	admin, err := b.chat.IsChatAdmin(chatID, from.ID)
+	if err != nil {
+		b.logger.Printf("Unable to check chat admin: %s", err)
+		return false
+	}
+	return admin
+}
+
+// attribute notes who made a change in a group chat
+func attribute(resp string, from sender) string {
+	if resp == "" {
+		return resp
+	}
+	return fmt.Sprintf("%s\n\n<i>Changed by %s</i>", resp, html.EscapeString(from.Name))
+}
+
+// incomingGroupMessage handles commands sent in a group chat
+// The group shares one watch list, which only the chat admins can change
+func (b *Handler) incomingGroupMessage(chatID int64, from sender, message string) error {
+	cmd, args, ok := b.parseCommand(message)
+	if !ok {
+		return nil
+	}
+
+	if !isChange(cmd) {
+		return b.reply(chatID, b.runCommand(chatID, cmd, args))
+	}
+	if !b.isGroupAdmin(chatID, from) {
+		return b.reply(chatID, groupAdminText)
+	}
+
+	return b.reply(chatID, attribute(b.runCommand(chatID, cmd, args), from))
+}
+
+// incomingGroupDocument handles files sent in a group chat
+func (b *Handler) incomingGroupDocument(chatID int64, from sender, caption, fileID string) error {
+	cmd, args, ok := b.parseCommand(caption)
+	if !ok || strings.ToLower(cmd) != "import" {
+		return nil
+	}
+	if !b.isGroupAdmin(chatID, from) {
+		return b.reply(chatID, groupAdminText)
+	}
+
+	return b.reply(chatID, attribute(b.handleImport(chatID, strings.ToLower(strings.TrimSpace(args)), fileID), from))
+}
+
+// incomingGroupCallback handles button presses in a group chat
+// Anyone can browse the watch list manager, but only the chat admins can change things
+func (b *Handler) incomingGroupCallback(chatID int64, from sender, messageID int, callbackID, data string) error {
+	if (!isItems(data) || isItemsChange(data)) && !b.isGroupAdmin(chatID, from) {
+		err := b.chat.AnswerCallback(callbackID, groupAdminText)
+		if err != nil {
+			return fmt.Errorf("Unable to answer callback: %v", err)
+		}
+		return nil
+	}
+
+	if isItems(data) {
+		text, rows := b.runItems(chatID, data)
+		return b.answerKeyboard(chatID, messageID, callbackID, text, rows)
+	}
+	return b.answer(chatID, messageID, callbackID, attribute(b.runCallback(chatID, data), from))
+}
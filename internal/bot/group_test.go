@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/telegram-bot-api.v4"
+)
+
+func TestMessageMention(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, message := range []string{"/selling@OtherBot foo", "/selling@mechkeybot foo"} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"add/1/foo",
+		"msg/1/Okay, I'm going to watch for <b>selling</b> posts that match <b>foo</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestGroupMessage(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	admin := sender{ID: 7, Name: "@alice"}
+	member := sender{ID: 8, Name: "Bob"}
+	for _, msg := range []struct {
+		from    sender
+		message string
+	}{
+		{member, "/selling@MechKeyBot foo"},
+		{member, "/items list"},
+		{member, "/style detailed"},
+		{member, "/app apollo"},
+		{member, "/rich on"},
+		{admin, "/selling@MechKeyBot foo"},
+		{admin, "/selling@OtherBot foo"},
+	} {
+		err := obj.incomingGroupMessage(-100, msg.from, msg.message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"msg/-100/" + groupAdminText,
+		"msg/-100/These are your current watch items:\n<b>SELLING:</b>\n - tada68 <i>(1 hits)</i>\n",
+		"msg/-100/" + groupAdminText,
+		"msg/-100/" + groupAdminText,
+		"msg/-100/" + groupAdminText,
+		"add/-100/foo",
+		"msg/-100/Okay, I'm going to watch for <b>selling</b> posts that match <b>foo</b>\n\n<i>Changed by @alice</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestGroupCallback(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	err := obj.incomingGroupCallback(-100, sender{ID: 8, Name: "Bob"}, 5, "cb", "clear selling")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	err = obj.incomingGroupCallback(-100, sender{ID: 7, Name: "@alice"}, 5, "cb", "clear selling")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+
+	expected := []string{
+		"answer/cb/" + groupAdminText,
+		"rm/-100/tada68",
+		"answer/cb/",
+		"edit/-100/5/Okay, I'm no longer watching for <b>1</b> items\n\n<i>Changed by @alice</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestIncomingUpdate(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, update := range []tgbotapi.Update{
+		{},
+		{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1, Type: "private"}, Text: "/selling foo"}},
+		{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100, Type: "supergroup"}, From: &tgbotapi.User{ID: 8, FirstName: "Bob"}, Text: "/selling foo"}},
+	} {
+		err := obj.incomingUpdate(update)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"add/1/foo",
+		"msg/1/Okay, I'm going to watch for <b>selling</b> posts that match <b>foo</b>",
+		"msg/-100/" + groupAdminText,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
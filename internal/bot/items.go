@@ -0,0 +1,355 @@
+package bot
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+)
+
+const (
+	// itemsPrefix starts the data of every watch list manager button
+	itemsPrefix = "items"
+	// itemsPerPage is how many keywords are shown at once
+	itemsPerPage = 8
+)
+
+// itemsSnoozes are the snooze buttons offered for a keyword
+var itemsSnoozes = []string{"1d", "1w"}
+
+const (
+	// followingList is the author list of followed authors
+	followingList = "following"
+	// blockedList is the author list of blocked authors
+	blockedList = "blocked"
+)
+
+// itemsLists are the lists shown after the types, their items are authors
+var itemsLists = []string{followingList, blockedList}
+
+// itemsData returns the data behind a type or author list
+func (b *Handler) itemsData(list string) (data.Interface, bool) {
+	switch list {
+	case followingList:
+		return b.follows, true
+	case blockedList:
+		return b.blocks, true
+	}
+	d, ok := b.data[list]
+	return d, ok
+}
+
+// isAuthorList checks if the items of a list are authors rather than keywords
+func isAuthorList(list string) bool {
+	return list == followingList || list == blockedList
+}
+
+// itemName describes an item of a list
+func itemName(list, item string) string {
+	if isAuthorList(list) {
+		return "/u/" + item
+	}
+	return item
+}
+
+// isItems checks if a button belongs to the watch list manager
+func isItems(data string) bool {
+	return data == itemsPrefix || strings.HasPrefix(data, itemsPrefix+" ")
+}
+
+// isItemsChange checks if a watch list manager button changes the watch list,
+// the others only browse it
+func isItemsChange(data string) bool {
+	fields := strings.Fields(strings.TrimPrefix(data, itemsPrefix))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "rm", "snooze", "wake":
+		return true
+	}
+	return false
+}
+
+// keywordHash shortens a keyword to fit in the 64 bytes of button data
+func keywordHash(keyword string) string {
+	h := fnv.New32a()
+	h.Write([]byte(keyword))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// sortedKeywords returns the items of a list in order
+func (b *Handler) sortedKeywords(userID int64, cmd string) []string {
+	d, _ := b.itemsData(cmd)
+	keys := []string{}
+	for k := range d.Get(userID) {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// findKeyword returns the item of a list that has the hash
+func (b *Handler) findKeyword(userID int64, cmd, hash string) (string, bool) {
+	for _, k := range b.sortedKeywords(userID, cmd) {
+		if keywordHash(k) == hash {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// handleItems sends the watch list manager, or the whole list as text with /items list
+func (b *Handler) handleItems(userID int64, args string) string {
+	if strings.EqualFold(strings.TrimSpace(args), "list") {
+		return b.handleWatchlist(userID)
+	}
+
+	text, rows := b.runItems(userID, itemsPrefix)
+	if len(rows) == 0 {
+		return text
+	}
+
+	err := b.chatFor(userID).SendKeyboard(userID, text, rows)
+	if err != nil {
+		b.logger.Println("Unable to send buttons: ", err)
+		return b.handleWatchlist(userID)
+	}
+
+	return ""
+}
+
+// runItems executes a watch list manager button and returns the new message and buttons
+func (b *Handler) runItems(userID int64, data string) (string, [][]chatter.Button) {
+	fields := strings.Fields(strings.TrimPrefix(data, itemsPrefix))
+	if len(fields) == 0 {
+		return b.itemsOverview(userID)
+	}
+	if fields[0] == "done" {
+		return "Okay, send /items to manage your watch list again", nil
+	}
+	if len(fields) < 3 {
+		return "That button doesn't do anything anymore", nil
+	}
+
+	cmd := fields[1]
+	d, ok := b.itemsData(cmd)
+	if !ok {
+		return "That button doesn't do anything anymore", nil
+	}
+	if fields[0] == "t" {
+		page, _ := strconv.Atoi(fields[2])
+		return b.itemsPage(userID, cmd, page, "")
+	}
+
+	// Keyword buttons carry the page they were shown from last, after the snooze duration
+	page := 0
+	pageField := 3
+	if fields[0] == "snooze" {
+		pageField = 4
+	}
+	if len(fields) > pageField {
+		page, _ = strconv.Atoi(fields[pageField])
+	}
+
+	keyword, ok := b.findKeyword(userID, cmd, fields[2])
+	if !ok {
+		return b.itemsPage(userID, cmd, page, "That isn't on your watch list anymore")
+	}
+
+	switch {
+	case fields[0] == "k":
+		return b.itemsKeyword(userID, cmd, keyword, page, "")
+
+	case fields[0] == "rm":
+		err := d.Remove(userID, keyword)
+		if err != nil {
+			b.logger.Println("Unable to remove keyword: ", err)
+		}
+		if !isAuthorList(cmd) {
+			b.clearJobs(userID, cmd, keyword)
+		}
+		return b.itemsPage(userID, cmd, page, removedText(cmd, keyword))
+
+	case isAuthorList(cmd):
+		return "That button doesn't do anything anymore", nil
+
+	case fields[0] == "snooze" && len(fields) >= 4:
+		duration, err := scheduler.ParseDuration(fields[3])
+		if err != nil {
+			return b.itemsKeyword(userID, cmd, keyword, page, "")
+		}
+		err = b.schedule.Add(scheduler.Job{Kind: snoozeJob, UserID: userID, Type: cmd, Keyword: keyword, At: time.Now().Add(duration)})
+		if err != nil {
+			b.logger.Println("Unable to snooze: ", err)
+		}
+		return b.itemsKeyword(userID, cmd, keyword, page, "Okay, I've snoozed it")
+
+	case fields[0] == "wake":
+		err := b.schedule.Remove(scheduler.Job{Kind: snoozeJob, UserID: userID, Type: cmd, Keyword: keyword})
+		if err != nil {
+			b.logger.Println("Unable to wake: ", err)
+		}
+		return b.itemsKeyword(userID, cmd, keyword, page, "Okay, I'm watching for it again")
+	}
+
+	return "That button doesn't do anything anymore", nil
+}
+
+// itemsOverview shows how many items there are of each type and author list, with a button to browse each
+func (b *Handler) itemsOverview(userID int64) (string, [][]chatter.Button) {
+	rows := [][]chatter.Button{}
+	row := []chatter.Button{}
+	for _, list := range append(append([]string{}, matcher.Types...), itemsLists...) {
+		d, _ := b.itemsData(list)
+		count := len(d.Get(userID))
+		if count == 0 {
+			continue
+		}
+		row = append(row, chatter.Button{Text: fmt.Sprintf("%s (%d)", list, count), Data: fmt.Sprintf("%s t %s 0", itemsPrefix, list)})
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = []chatter.Button{}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return "There are no items on your watch list", nil
+	}
+	return "Choose a list to manage", append(rows, []chatter.Button{{Text: "Done", Data: itemsPrefix + " done"}})
+}
+
+// itemsPage shows a page of the items of a list, with a button for each
+func (b *Handler) itemsPage(userID int64, cmd string, page int, note string) (string, [][]chatter.Button) {
+	d, _ := b.itemsData(cmd)
+	hits := d.Get(userID)
+	keys := b.sortedKeywords(userID, cmd)
+	if len(keys) == 0 {
+		text, rows := b.itemsOverview(userID)
+		if note != "" {
+			text = fmt.Sprintf("%s\n\n%s", note, text)
+		}
+		return text, rows
+	}
+
+	// Removing the last item of the last page shows the page before it
+	pages := (len(keys) + itemsPerPage - 1) / itemsPerPage
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	end := (page + 1) * itemsPerPage
+	if end > len(keys) {
+		end = len(keys)
+	}
+
+	rows := [][]chatter.Button{}
+	for _, k := range keys[page*itemsPerPage : end] {
+		rows = append(rows, []chatter.Button{{Text: fmt.Sprintf("%s (%d hits)", itemName(cmd, k), hits[k]), Data: fmt.Sprintf("%s k %s %s %d", itemsPrefix, cmd, keywordHash(k), page)}})
+	}
+
+	nav := []chatter.Button{}
+	if page > 0 {
+		nav = append(nav, chatter.Button{Text: "« Previous", Data: fmt.Sprintf("%s t %s %d", itemsPrefix, cmd, page-1)})
+	}
+	nav = append(nav, chatter.Button{Text: "Back", Data: itemsPrefix})
+	if page < pages-1 {
+		nav = append(nav, chatter.Button{Text: "Next »", Data: fmt.Sprintf("%s t %s %d", itemsPrefix, cmd, page+1)})
+	}
+
+	kind := "keywords"
+	if isAuthorList(cmd) {
+		kind = "authors"
+	}
+	text := fmt.Sprintf("<b>%s</b> %s, page %d of %d", strings.ToUpper(cmd), kind, page+1, pages)
+	if note != "" {
+		text = fmt.Sprintf("%s\n\n%s", note, text)
+	}
+	return text, append(rows, nav)
+}
+
+// itemsKeyword shows the details of a keyword or author, with buttons to remove or snooze it
+// and to go back to the page it was shown on
+func (b *Handler) itemsKeyword(userID int64, cmd, keyword string, page int, note string) (string, [][]chatter.Button) {
+	d, _ := b.itemsData(cmd)
+	details := d.Details(userID, keyword)
+	sub := matcher.ParseSubscription(keyword)
+	hash := keywordHash(keyword)
+	back := []chatter.Button{{Text: "Back", Data: fmt.Sprintf("%s t %s %d", itemsPrefix, cmd, page)}}
+
+	resp := []string{
+		fmt.Sprintf("<b>%s:</b> %s", strings.ToUpper(cmd), html.EscapeString(itemName(cmd, keyword))),
+		fmt.Sprintf(" - Added: %s", itemsTime(details.Created)),
+		fmt.Sprintf(" - Last hit: %s", itemsTime(details.LastHit)),
+		fmt.Sprintf(" - Hits: %d", d.Get(userID)[keyword]),
+	}
+	remove := []chatter.Button{{Text: "Remove", Data: fmt.Sprintf("%s rm %s %s %d", itemsPrefix, cmd, hash, page)}}
+
+	// Authors don't have options and can't be snoozed
+	if isAuthorList(cmd) {
+		return strings.Join(resp, "\n"), [][]chatter.Button{remove, back}
+	}
+
+	options := []string{"in " + sub.Scope}
+	if sub.Trades {
+		options = append(options, "with trades")
+	}
+	if sub.MinRep > 0 {
+		options = append(options, fmt.Sprintf("at least %d trades", sub.MinRep))
+	}
+	if sub.Once {
+		options = append(options, "until the first match")
+	}
+	if job, ok := b.schedule.Find(scheduler.Job{Kind: expireJob, UserID: userID, Type: cmd, Keyword: keyword}); ok {
+		options = append(options, "until "+job.At.UTC().Format(timeFormat))
+	}
+	resp = append(resp, fmt.Sprintf(" - Options: %s", strings.Join(options, ", ")))
+
+	actions := remove
+	if job, ok := b.schedule.Find(scheduler.Job{Kind: snoozeJob, UserID: userID, Type: cmd, Keyword: keyword}); ok {
+		resp = append(resp, fmt.Sprintf(" - Snoozed until: %s", job.At.UTC().Format(timeFormat)))
+		actions = append(actions, chatter.Button{Text: "Wake", Data: fmt.Sprintf("%s wake %s %s %d", itemsPrefix, cmd, hash, page)})
+	} else {
+		for _, d := range itemsSnoozes {
+			actions = append(actions, chatter.Button{Text: "Snooze " + d, Data: fmt.Sprintf("%s snooze %s %s %s %d", itemsPrefix, cmd, hash, d, page)})
+		}
+	}
+
+	text := strings.Join(resp, "\n")
+	if note != "" {
+		text = fmt.Sprintf("%s\n\n%s", note, text)
+	}
+	return text, [][]chatter.Button{actions, back}
+}
+
+// removedText describes an item that was taken off a list
+func removedText(list, item string) string {
+	switch list {
+	case followingList:
+		return fmt.Sprintf("I'm no longer following posts from <b>/u/%s</b>", html.EscapeString(item))
+	case blockedList:
+		return fmt.Sprintf("I'm no longer ignoring posts from <b>/u/%s</b>", html.EscapeString(item))
+	}
+	return fmt.Sprintf("I'm no longer watching for <b>%s</b> posts that match <b>%s</b>", html.EscapeString(list), html.EscapeString(item))
+}
+
+// itemsTime shows a date, or never if it hasn't happened
+func itemsTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.UTC().Format(timeFormat)
+}
@@ -0,0 +1,239 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestKeywordHash(t *testing.T) {
+	actual := keywordHash("tada68")
+	if len(actual) != 8 {
+		t.Errorf("Expected %q to be 8 characters", actual)
+	}
+	if actual != keywordHash("tada68") || actual == keywordHash("tada68 once") {
+		t.Errorf("Expected %q to only match the same keyword", actual)
+	}
+}
+
+func TestMessageItems(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.follows = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"alice": 2}
+		},
+	}
+
+	err := obj.incomingMessage(1, "/items")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+
+	expected := []string{
+		"keyboard/1/Choose a list to manage/[[{selling (1) items t selling 0} {following (1) items t following 0}] [{Done items done}]]",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestCallbackItems(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.data[matcher.Selling].(*mocks.Data).MockDetails = func(int64, string) data.Details {
+		return data.Details{Created: time.Date(2018, 1, 2, 15, 4, 0, 0, time.UTC)}
+	}
+	snoozed := false
+	obj.schedule = &mocks.Scheduler{
+		MockAdd: func(j scheduler.Job) error {
+			actual = append(actual, fmt.Sprintf("add/%s/%s/%s", j.Kind, j.Type, j.Keyword))
+			snoozed = true
+			return nil
+		},
+		MockFind: func(j scheduler.Job) (scheduler.Job, bool) {
+			j.At = time.Date(2018, 1, 3, 15, 4, 0, 0, time.UTC)
+			return j, snoozed && j.Kind == snoozeJob
+		},
+		MockRemove: func(j scheduler.Job) error {
+			actual = append(actual, fmt.Sprintf("remove/%s/%s/%s", j.Kind, j.Type, j.Keyword))
+			return nil
+		},
+	}
+
+	hash := keywordHash("tada68")
+	for _, data := range []string{
+		"items t selling 0",
+		"items k selling " + hash,
+		"items snooze selling " + hash + " 1d",
+		"items rm selling " + hash,
+		"items k selling 00000000",
+		"items done",
+	} {
+		err := obj.incomingCallback(1, 1, 5, "cb", data)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"answer/cb/",
+		"editkeyboard/1/5/<b>SELLING</b> keywords, page 1 of 1/[[{tada68 (1 hits) items k selling " + hash + " 0}] [{Back items}]]",
+		"answer/cb/",
+		"editkeyboard/1/5/<b>SELLING:</b> tada68\n - Added: Jan 2 15:04 UTC\n - Last hit: never\n - Hits: 1\n - Options: in title/[[{Remove items rm selling " + hash + " 0} {Snooze 1d items snooze selling " + hash + " 1d 0} {Snooze 1w items snooze selling " + hash + " 1w 0}] [{Back items t selling 0}]]",
+		"add/snooze/selling/tada68",
+		"answer/cb/",
+		"editkeyboard/1/5/Okay, I've snoozed it\n\n<b>SELLING:</b> tada68\n - Added: Jan 2 15:04 UTC\n - Last hit: never\n - Hits: 1\n - Options: in title\n - Snoozed until: Jan 3 15:04 UTC/[[{Remove items rm selling " + hash + " 0} {Wake items wake selling " + hash + " 0}] [{Back items t selling 0}]]",
+		"rm/1/tada68",
+		"remove/snooze/selling/tada68",
+		"remove/expire/selling/tada68",
+		"answer/cb/",
+		"editkeyboard/1/5/I'm no longer watching for <b>selling</b> posts that match <b>tada68</b>\n\n<b>SELLING</b> keywords, page 1 of 1/[[{tada68 (1 hits) items k selling " + hash + " 0}] [{Back items}]]",
+		"answer/cb/",
+		"editkeyboard/1/5/That isn't on your watch list anymore\n\n<b>SELLING</b> keywords, page 1 of 1/[[{tada68 (1 hits) items k selling " + hash + " 0}] [{Back items}]]",
+		"answer/cb/",
+		"editkeyboard/1/5/Okay, send /items to manage your watch list again/[]",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestItemsAuthors(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.follows = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"alice": 2}
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("unfollow/%d/%s", i, s))
+			return nil
+		},
+	}
+
+	hash := keywordHash("alice")
+	for _, data := range []string{
+		"items t following 0",
+		"items k following " + hash,
+		"items snooze following " + hash + " 1d",
+		"items rm following " + hash,
+	} {
+		err := obj.incomingCallback(1, 1, 5, "cb", data)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"answer/cb/",
+		"editkeyboard/1/5/<b>FOLLOWING</b> authors, page 1 of 1/[[{/u/alice (2 hits) items k following " + hash + " 0}] [{Back items}]]",
+		"answer/cb/",
+		"editkeyboard/1/5/<b>FOLLOWING:</b> /u/alice\n - Added: never\n - Last hit: never\n - Hits: 2/[[{Remove items rm following " + hash + " 0}] [{Back items t following 0}]]",
+		"answer/cb/",
+		"editkeyboard/1/5/That button doesn't do anything anymore/[]",
+		"unfollow/1/alice",
+		"answer/cb/",
+		"editkeyboard/1/5/I'm no longer following posts from <b>/u/alice</b>\n\n<b>FOLLOWING</b> authors, page 1 of 1/[[{/u/alice (2 hits) items k following " + hash + " 0}] [{Back items}]]",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestItemsPages(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	keywords := data.Keywords{}
+	for i := 0; i < 10; i++ {
+		keywords[fmt.Sprintf("k%d", i)] = i
+	}
+	obj.data[matcher.Selling].(*mocks.Data).MockGet = func(int64) data.Keywords {
+		return keywords
+	}
+
+	text, rows := obj.runItems(1, "items t selling 0")
+	if text != "<b>SELLING</b> keywords, page 1 of 2" || len(rows) != 9 {
+		t.Errorf("Expected the first page of 8, got %q with %d rows", text, len(rows))
+	}
+	expected := []chatter.Button{{Text: "Back", Data: "items"}, {Text: "Next »", Data: "items t selling 1"}}
+	if !reflect.DeepEqual(rows[8], expected) {
+		t.Errorf("Expected %q to equal %q", rows[8], expected)
+	}
+
+	text, rows = obj.runItems(1, "items t selling 1")
+	if text != "<b>SELLING</b> keywords, page 2 of 2" || len(rows) != 3 {
+		t.Errorf("Expected the second page of 2, got %q with %d rows", text, len(rows))
+	}
+	expected = []chatter.Button{{Text: "« Previous", Data: "items t selling 0"}, {Text: "Back", Data: "items"}}
+	if !reflect.DeepEqual(rows[2], expected) {
+		t.Errorf("Expected %q to equal %q", rows[2], expected)
+	}
+
+	// Details and removing go back to the page the keyword was on
+	_, rows = obj.runItems(1, "items k selling "+keywordHash("k9")+" 1")
+	if back := rows[1][0].Data; back != "items t selling 1" {
+		t.Errorf("Expected to go back to the second page, got %q", back)
+	}
+	obj.data[matcher.Selling].(*mocks.Data).MockRemove = func(i int64, s string) error {
+		delete(keywords, s)
+		return nil
+	}
+	text, _ = obj.runItems(1, "items rm selling "+keywordHash("k9")+" 1")
+	if !strings.HasSuffix(text, "page 2 of 2") {
+		t.Errorf("Expected the second page, got %q", text)
+	}
+	text, _ = obj.runItems(1, "items rm selling "+keywordHash("k8")+" 1")
+	if !strings.HasSuffix(text, "page 1 of 1") {
+		t.Errorf("Expected the only page left, got %q", text)
+	}
+}
+
+func TestGroupItems(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, data := range []string{"items", "items rm selling " + keywordHash("tada68") + " 0"} {
+		err := obj.incomingGroupCallback(-100, sender{ID: 8, Name: "Bob"}, 5, "cb", data)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"answer/cb/",
+		"editkeyboard/-100/5/Choose a list to manage/[[{selling (1) items t selling 0}] [{Done items done}]]",
+		"answer/cb/" + groupAdminText,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestSlackItems(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.slack.(*mocks.Slack).MockRespond = func(u, s string, blocks []slack.Block, replace bool) error {
+		actual = append(actual, fmt.Sprintf("respond/%s/%s/%d/%t", u, s, len(blocks), replace))
+		return nil
+	}
+
+	err := obj.incomingSlack(slack.Event{Team: "T1", User: "U1", Action: "items t selling 0", ResponseURL: "r1"})
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+
+	expected := []string{"respond/r1/*SELLING* keywords, page 1 of 1/3/true"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/identity"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+// telegramReplier sends the bot's own messages to a Telegram chat linked to
+// a user from another transport
+type telegramReplier struct {
+	chat   chatter.Interface
+	chatID int64
+}
+
+// SendMessage sends a message to the chat
+func (r telegramReplier) SendMessage(_ int64, message string) error {
+	return r.chat.SendMessage(r.chatID, message)
+}
+
+// SendButtons sends a message with a row of buttons to the chat
+func (r telegramReplier) SendButtons(_ int64, message string, buttons []chatter.Button) error {
+	return r.chat.SendButtons(r.chatID, message, buttons)
+}
+
+// SendKeyboard sends a message with rows of buttons to the chat
+func (r telegramReplier) SendKeyboard(_ int64, message string, rows [][]chatter.Button) error {
+	return r.chat.SendKeyboard(r.chatID, message, rows)
+}
+
+// SendDocument sends a file to the chat
+func (r telegramReplier) SendDocument(_ int64, name string, contents []byte, caption string) error {
+	return r.chat.SendDocument(r.chatID, name, contents, caption)
+}
+
+// heard remembers where a user last talked to the bot from, so replies go back there
+func (b *Handler) heard(userID int64, addr notifier.Address) {
+	if b.origins == nil {
+		b.origins = make(map[int64]notifier.Address)
+	}
+	b.origins[userID] = addr
+}
+
+// origin returns where a user last talked to the bot from, or their home
+// address if they haven't since the bot started
+func (b *Handler) origin(userID int64) notifier.Address {
+	if addr, ok := b.origins[userID]; ok {
+		return addr
+	}
+	return b.home(userID)
+}
+
+// fromAdmin checks if a user is talking to the bot from an admin Telegram chat
+// Admin rights stay with the chat, they don't carry over to accounts linked to it
+func (b *Handler) fromAdmin(userID int64) bool {
+	addr := b.origin(userID)
+	if addr.Transport != notifier.Telegram {
+		return false
+	}
+	chatID, err := strconv.ParseInt(addr.Target, 10, 64)
+	return err == nil && b.isAdmin(chatID)
+}
+
+// telegramUser returns the user ID of a private Telegram chat, which is the
+// chat ID unless the chat was linked to another account
+func (b *Handler) telegramUser(chatID int64) int64 {
+	userID, err := b.identities.Claim(notifier.Telegram, strconv.FormatInt(chatID, 10), chatID)
+	if err != nil {
+		b.logger.Printf("Unable to save identity: %s", err)
+	}
+	b.heard(userID, notifier.Address{Transport: notifier.Telegram, Target: strconv.FormatInt(chatID, 10)})
+	return userID
+}
+
+// migrateTelegram adds the Telegram chats from before identities to the directory
+func (b *Handler) migrateTelegram() {
+	for _, userID := range b.users() {
+		if userID <= 0 || userID >= identity.Offset {
+			continue
+		}
+		_, err := b.identities.Claim(notifier.Telegram, strconv.FormatInt(userID, 10), userID)
+		if err != nil {
+			b.logger.Printf("Unable to migrate @%d: %s", userID, err)
+			return
+		}
+	}
+}
+
+func (b *Handler) handleLink(userID int64, args string) string {
+	if userID < 0 {
+		return "Linking only works in a private chat with me"
+	}
+
+	code := strings.TrimSpace(args)
+	if code == "" {
+		code, err := b.identities.NewCode(userID)
+		if err != nil {
+			b.logger.Println("Unable to create code: ", err)
+			return "Sorry, I wasn't able to create a code"
+		}
+		return fmt.Sprintf("Send <code>/link %s</code> from your other chat (<code>/mm-link %s</code> on Slack) within an hour to share this watch list with it", code, code)
+	}
+
+	// Remember where the old account is before it moves
+	from := b.home(userID)
+	target, ok, err := b.identities.Redeem(code, userID)
+	if err == identity.ErrLocked {
+		return "Too many wrong codes have been sent, try again in an hour"
+	}
+	if err != nil {
+		b.logger.Println("Unable to save identity: ", err)
+		return "Sorry, I wasn't able to link that"
+	}
+	if !ok {
+		return "That code doesn't match or has expired, send /link from your other chat to get a new one"
+	}
+	if target == userID {
+		return "This chat already shares that watch list"
+	}
+
+	moved := b.mergeUser(userID, target)
+	if from.Transport != b.home(target).Transport && b.settings.Get(target, notifyKey+from.Transport) == "" {
+		err = b.settings.Set(target, notifyKey+from.Transport, from.Target)
+		if err != nil {
+			b.logger.Println("Unable to save setting: ", err)
+		}
+	}
+	b.heard(target, b.origin(userID))
+
+	return fmt.Sprintf("Okay, this chat now shares the watch list from <b>%s</b>, with <b>%d</b> items moved over from here", html.EscapeString(b.home(target).Transport), moved)
+}
+
+// mergeUser moves the watches, blocks and follows of one user ID to another,
+// returning how many were moved
+func (b *Handler) mergeUser(from, to int64) int {
+	moved := 0
+	move := func(d data.Interface, cmd string) {
+		for keyword := range d.Get(from) {
+			if !d.Exists(to, keyword) {
+				err := d.Add(to, keyword)
+				if err != nil {
+					b.logger.Println("Unable to add keyword: ", err)
+					continue
+				}
+				if cmd != "" {
+					b.scheduleExpiry(to, cmd, keyword)
+				}
+				moved++
+			}
+
+			err := d.Remove(from, keyword)
+			if err != nil {
+				b.logger.Println("Unable to remove keyword: ", err)
+			}
+			if cmd != "" {
+				b.clearJobs(from, cmd, keyword)
+			}
+		}
+	}
+
+	for _, t := range matcher.Types {
+		move(b.data[t], t)
+	}
+	move(b.blocks, "")
+	move(b.follows, "")
+
+	return moved
+}
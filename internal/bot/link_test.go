@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestHandleLink(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, event := range []slack.Event{
+		{Team: "T1", User: "U1", Command: "/mm-link", Text: "99999999", ResponseURL: "r1"},
+		{Team: "T1", User: "U1", Command: "/mm-link", Text: "00000002", ResponseURL: "r2"},
+		{Team: "T1", User: "U1", Command: "/mm-link", Text: "00000001", ResponseURL: "r3"},
+	} {
+		err := obj.incomingSlack(event)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+	err := obj.incomingMessage(1, "/link")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+
+	expected := []string{
+		"respond/r1/That code doesn't match or has expired, send /link from your other chat to get a new one/false",
+		"respond/r2/This chat already shares that watch list/false",
+		"add/1/tada68",
+		fmt.Sprintf("rm/%d/tada68", slackID),
+		"set/1/notify.slack/U1",
+		"respond/r3/Okay, this chat now shares the watch list from *telegram*, with *1* items moved over from here/false",
+		"post/U1/Send `/link 00000001` from your other chat (`/mm-link 00000001` on Slack) within an hour to share this watch list with it/0",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	actual = []string{}
+	err = obj.incomingMessage(-100, "/link")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected = []string{"msg/-100/Linking only works in a private chat with me"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestChatForLinked(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.identities.(*mocks.Identity).MockClaim = func(transport, account string, i int64) (int64, error) {
+		if account == "5" {
+			return slackID, nil
+		}
+		return i, nil
+	}
+
+	if userID := obj.telegramUser(5); userID != slackID {
+		t.Errorf("Expected the linked chat to be %d, got %d", slackID, userID)
+	}
+	obj.chatFor(slackID).SendMessage(slackID, "via telegram")
+	obj.heard(slackID, notifier.Address{Transport: notifier.Slack, Target: "U1"})
+	obj.chatFor(slackID).SendMessage(slackID, "via slack")
+	obj.chatFor(3).SendMessage(3, "via home")
+
+	expected := []string{
+		"msg/5/via telegram",
+		"post/U1/via slack/0",
+		"msg/3/via home",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestLinkedAdmin(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.identities.(*mocks.Identity).MockResolve = func(transport, account string) (int64, error) {
+		return 1, nil
+	}
+
+	err := obj.incomingSlack(slack.Event{Team: "T1", User: "U1", Command: "/mm", Text: "broadcast hi", ResponseURL: "r1"})
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+
+	expected := []string{"respond/r1/That command doesn't look like anything to me./false"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+	if obj.telegramUser(1); !obj.fromAdmin(1) {
+		t.Errorf("Expected the admin chat to keep its rights")
+	}
+}
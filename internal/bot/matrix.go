@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+// matrixReplyPrefix starts a reply that stands in for a button press, since
+// Matrix messages can't have buttons (e.g. !clear all)
+const matrixReplyPrefix = "!"
+
+// matrixReplier sends the bot's own messages to a Matrix room
+type matrixReplier struct {
+	app  matrix.Interface
+	room string
+}
+
+// SendMessage sends an HTML message
+func (r matrixReplier) SendMessage(_ int64, message string) error {
+	return r.app.SendMessage(r.room, matrix.Plain(message), message)
+}
+
+// SendButtons sends a message listing the replies that stand in for each button
+func (r matrixReplier) SendButtons(_ int64, message string, buttons []chatter.Button) error {
+	lines := []string{message, ""}
+	for _, button := range buttons {
+		lines = append(lines, fmt.Sprintf(" - <b>%s</b>: reply <code>%s%s</code>", html.EscapeString(button.Text), matrixReplyPrefix, html.EscapeString(button.Data)))
+	}
+	return r.SendMessage(0, strings.Join(lines, "\n"))
+}
+
+// SendKeyboard sends a message listing the replies that stand in for every button
+func (r matrixReplier) SendKeyboard(userID int64, message string, rows [][]chatter.Button) error {
+	buttons := []chatter.Button{}
+	for _, row := range rows {
+		buttons = append(buttons, row...)
+	}
+	return r.SendButtons(userID, message, buttons)
+}
+
+// SendDocument sends the file as a code block, since the bot can't upload files
+func (r matrixReplier) SendDocument(_ int64, name string, contents []byte, caption string) error {
+	return r.SendMessage(0, fmt.Sprintf("%s\n<b>%s</b>\n<pre><code>%s</code></pre>", html.EscapeString(caption), html.EscapeString(name), html.EscapeString(string(contents))))
+}
+
+// isRoomAdmin checks if the sender is allowed to change the room's watch list
+func (b *Handler) isRoomAdmin(event matrix.Event) bool {
+	admin, err := b.matrix.IsRoomAdmin(event.Room, event.Sender)
+	if err != nil {
+		b.logger.Printf("Unable to check room admin: %s", err)
+		return false
+	}
+	return admin
+}
+
+// incomingMatrix handles commands and button replies sent to a Matrix room
+// Each room gets its own watcher user ID above identity.Offset, unless linked
+func (b *Handler) incomingMatrix(event matrix.Event) error {
+	userID, err := b.identities.Resolve(notifier.Matrix, event.Room)
+	if err != nil {
+		b.logger.Printf("Unable to save identity: %s", err)
+	}
+	if b.banned(userID) {
+		return nil
+	}
+	b.heard(userID, notifier.Address{Transport: notifier.Matrix, Target: event.Room})
+
+	// A room shares one watch list, which only its admins can change
+	body := strings.TrimSpace(event.Body)
+	if !strings.HasPrefix(body, matrixReplyPrefix) {
+		cmd, args, ok := b.parseCommand(body)
+		if !ok {
+			return nil
+		}
+		if isChange(cmd) && !b.isRoomAdmin(event) {
+			return b.reply(userID, groupAdminText)
+		}
+		return b.reply(userID, b.runCommand(userID, cmd, args))
+	}
+
+	// Replies stand in for buttons, anyone can browse but only the room's admins can change things
+	data := strings.TrimPrefix(body, matrixReplyPrefix)
+	if (!isItems(data) || isItemsChange(data)) && !b.isRoomAdmin(event) {
+		return b.reply(userID, groupAdminText)
+	}
+
+	// Matrix has no buttons to edit, so the watch list manager sends a new message each time
+	if !isItems(data) {
+		return b.reply(userID, b.runCallback(userID, data))
+	}
+	text, rows := b.runItems(userID, data)
+	if len(rows) == 0 {
+		return b.reply(userID, text)
+	}
+	err = b.chatFor(userID).SendKeyboard(userID, text, rows)
+	if err != nil {
+		return fmt.Errorf("Unable to send message: %v", err)
+	}
+	return nil
+}
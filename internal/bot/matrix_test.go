@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+func TestIncomingMatrix(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, msg := range []struct {
+		sender string
+		body   string
+	}{
+		{"@alice:example.com", "/selling foo"},
+		{"@alice:example.com", "just chatting"},
+		{"@alice:example.com", "/clear selling"},
+		{"@bob:example.com", "!clear selling"},
+		{"@bob:example.com", "/notify"},
+		{"@bob:example.com", "/selling bar"},
+		{"@alice:example.com", "!clear selling"},
+		{"@alice:example.com", "!nope"},
+	} {
+		err := obj.incomingMatrix(matrix.Event{Room: "!room:example.com", Sender: msg.sender, Body: msg.body})
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		fmt.Sprintf("add/%d/foo", matrixID),
+		"matrix/!room:example.com/Okay, I'm going to watch for selling posts that match foo",
+		"matrix/!room:example.com/Are you sure you want to stop watching 1 items (selling)?\n\n - Yes, clear them: reply !clear selling\n - No, keep them: reply !cancel",
+		"matrix/!room:example.com/" + groupAdminText,
+		"matrix/!room:example.com/" + groupAdminText,
+		"matrix/!room:example.com/" + groupAdminText,
+		fmt.Sprintf("rm/%d/tada68", matrixID),
+		"matrix/!room:example.com/Okay, I'm no longer watching for 1 items",
+		"matrix/!room:example.com/That button doesn't do anything anymore",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMatrixHome(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	expected := notifier.Address{Transport: notifier.Matrix, Target: "!room:example.com"}
+	if home := obj.home(matrixID); !reflect.DeepEqual(home, expected) {
+		t.Errorf("Expected %+v to equal %+v", home, expected)
+	}
+	if _, ok := obj.chatFor(matrixID).(matrixReplier); !ok {
+		t.Errorf("Expected Matrix rooms to be sent Matrix messages")
+	}
+}
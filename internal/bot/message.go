@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
 	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
 )
 
@@ -15,14 +16,46 @@ var helpText = `
 You can subscribe or unsubscribe to events by using the following commands:
  /buying <keyword> - something being bought
  /selling <keyword> - something being sold
+ /trading <keyword> - something being traded for other items
  /vendor <keyword> - updates from vendors
  /artisan <keyword> - updates from artisans
  /groupbuy <keyword> - updates about group buys
  /interestcheck <keyword> - feedback about a design
  /giveaway <keyword> - something being given away
+ /watch <type> <keyword> - start watching, never removes a watch (e.g. /watch selling tada68, tofu)
+ /unwatch <type> <keyword> - stop watching, never adds a watch (e.g. /unwatch selling tada68, tofu)
+ /clear [type] - stop watching everything, or everything of one type (e.g. /clear selling)
+
+Watch several keywords at once by separating them with commas or new lines (e.g. /selling tada68, tofu, kbd67)
+
+Add "trades" to the end of /selling to include trades (e.g. /selling tada68 trades)
+
+Add "minrep:10" to only match authors with at least 10 confirmed trades (e.g. /selling gmk minrep:10)
+
+Add "once" to stop watching after the first match, or "for:14d" to stop watching after two weeks (e.g. /selling tada68 once)
+
+Keywords only match the post title, add "in:items" to match the item list or "in:any" to match anywhere (e.g. /selling tada68 in:items)
+
+Authors:
+ /block <username> - never notify me about posts from this user
+ /unblock <username> - stop blocking this user
+ /follow <username> - notify me about every post from this user
+ /unfollow <username> - stop following this user
+
+Pausing:
+ /pause [duration] - stop all notifications, forever or for a while (e.g. /pause 3d)
+ /resume - start notifications again
+ /snooze <type> <keyword> <duration> - stop one watch for a while (e.g. /snooze selling tada68 12h)
 
 Other options:
- /items - returns list of watched items
+ /notify - choose where matches are sent, like a Discord channel
+ /link [code] - share this watch list with your chat on another app
+ /style [style] - choose how matches look, like with the price
+ /app [app] - choose which app the app link opens
+ /rich [on|off] - add the part of the post that matched and its photo
+ /items - browse, remove, and snooze your watched items (/items list for plain text)
+ /export - sends your watch list as a file
+ /import - loads a watch list file from /export
  /stats - returns stats about the current bot
  /help - gets this help message
 `
@@ -37,25 +70,112 @@ If you want to be notified about the next Fugu sale, just tell me:
 What if you want to be notified about ALL artisan posts, send me this:
  /artisan
 
-Unsubscribe at anytime by sending the same message (e.g. /selling tada68). Learn more with /help`
+Unsubscribe at anytime by sending the same message (e.g. /selling tada68) or with /unwatch (e.g. /unwatch selling tada68). Learn more with /help`
 
-// /COMMAND OPTIONALDATA
-var cmdRex = regexp.MustCompile(`(?i)^/(\w+)(?:\s(.+))?$`)
+// /COMMAND[@BOTNAME] OPTIONALDATA
+var cmdRex = regexp.MustCompile(`(?is)^/(\w+)(?:@(\w+))?(?:\s(.+))?$`)
 
-func (b *Handler) incomingMessage(userID int64, message string) error {
+// parseCommand splits a message into the command and its data
+// Commands addressed to a different bot are ignored
+func (b *Handler) parseCommand(message string) (string, string, bool) {
 	fields := cmdRex.FindStringSubmatch(message)
 	if fields == nil {
+		return "", "", false
+	}
+	if fields[2] != "" && !strings.EqualFold(fields[2], b.username) {
+		return "", "", false
+	}
+	return fields[1], fields[3], true
+}
+
+func (b *Handler) incomingMessage(userID int64, message string) error {
+	cmd, args, ok := b.parseCommand(message)
+	if !ok {
+		return nil
+	}
+
+	return b.reply(userID, b.runCommand(userID, cmd, args))
+}
+
+// reply sends the response to a command, some commands reply on their own
+func (b *Handler) reply(userID int64, resp string) error {
+	if resp == "" {
 		return nil
 	}
 
+	err := b.chatFor(userID).SendMessage(userID, resp)
+	if err != nil {
+		return fmt.Errorf("Unable to send message: %v", err)
+	}
+
+	return nil
+}
+
+// runCommand executes a command and returns the response
+func (b *Handler) runCommand(userID int64, cmd, args string) string {
+	if adminCommands[cmd] && !b.fromAdmin(userID) {
+		return "That command doesn't look like anything to me."
+	}
+
 	var resp string
-	switch cmd := fields[1]; cmd {
-	case matcher.Buying, matcher.Selling, matcher.Artisan, matcher.Vendor,
+	switch cmd {
+	case matcher.Buying, matcher.Selling, matcher.Trading, matcher.Artisan, matcher.Vendor,
 		matcher.GroupBuy, matcher.InterestCheck, matcher.Giveaway:
-		resp = b.handleSubscribe(userID, cmd, fields[2])
+		resp = b.handleSubscribe(userID, cmd, args)
+
+	case "watch":
+		resp = b.handleWatch(userID, args)
+
+	case "unwatch":
+		resp = b.handleUnwatch(userID, args)
+
+	case "clear":
+		resp = b.handleClear(userID, args)
+
+	case "block":
+		resp = b.handleBlock(userID, args)
+
+	case "unblock":
+		resp = b.handleUnblock(userID, args)
+
+	case "follow":
+		resp = b.handleFollow(userID, args)
+
+	case "unfollow":
+		resp = b.handleUnfollow(userID, args)
+
+	case "pause":
+		resp = b.handlePause(userID, args)
+
+	case "resume":
+		resp = b.handleResume(userID)
+
+	case "snooze":
+		resp = b.handleSnooze(userID, args)
+
+	case "notify":
+		resp = b.handleNotify(userID, args)
+
+	case "link":
+		resp = b.handleLink(userID, args)
+
+	case "style":
+		resp = b.handleStyle(userID, args)
+
+	case "app":
+		resp = b.handleApp(userID, args)
+
+	case "rich":
+		resp = b.handleRich(userID, args)
 
 	case "items":
-		resp = b.handleWatchlist(userID)
+		resp = b.handleItems(userID, args)
+
+	case "export":
+		resp = b.handleExport(userID)
+
+	case "import":
+		resp = importText
 
 	case "stats":
 		resp = b.handleStats()
@@ -66,21 +186,46 @@ func (b *Handler) incomingMessage(userID int64, message string) error {
 	case "help":
 		resp = b.handleHelp()
 
+	case "synonyms":
+		resp = b.handleSynonyms()
+
+	case "unparsed":
+		resp = b.handleUnparsed()
+
+	case "broadcast":
+		resp = b.handleBroadcast(args)
+
+	case "users":
+		resp = b.handleUsers()
+
+	case "ban":
+		resp = b.handleBan(args)
+
+	case "unban":
+		resp = b.handleUnban(args)
+
+	case "reload":
+		resp = b.handleReload()
+
+	case "health":
+		resp = b.handleHealth()
+
 	default:
 		resp = "That command doesn't look like anything to me."
 	}
 
-	err := b.chat.SendMessage(userID, resp)
-	if err != nil {
-		return fmt.Errorf("Unable to send message: %v", err)
-	}
-
-	return nil
+	return resp
 }
 
-func (b *Handler) handleSubscribe(userID int64, cmd, keyword string) string {
-	if keyword == "" {
-		keyword = "*"
+func (b *Handler) handleSubscribe(userID int64, cmd, list string) string {
+	keywords := splitKeywords(list)
+	if len(keywords) > 1 {
+		return b.handleBulkSubscribe(userID, cmd, keywords)
+	}
+
+	keyword := "*"
+	if len(keywords) == 1 {
+		keyword = keywords[0]
 	}
 
 	if b.data[cmd].Exists(userID, keyword) {
@@ -88,6 +233,7 @@ func (b *Handler) handleSubscribe(userID int64, cmd, keyword string) string {
 		if err != nil {
 			b.logger.Println("Unable to remove keyword: ", err)
 		}
+		b.clearJobs(userID, cmd, keyword)
 
 		return fmt.Sprintf("I'm no longer watching for <b>%s</b> posts that match <b>%s</b>", html.EscapeString(cmd), html.EscapeString(keyword))
 	}
@@ -98,7 +244,7 @@ func (b *Handler) handleSubscribe(userID int64, cmd, keyword string) string {
 	}
 
 	// @TODO better message for ALL events
-	return fmt.Sprintf("Okay, I'm going to watch for <b>%s</b> posts that match <b>%s</b>", html.EscapeString(cmd), html.EscapeString(keyword))
+	return fmt.Sprintf("Okay, I'm going to watch for <b>%s</b> posts that match <b>%s</b>%s", html.EscapeString(cmd), html.EscapeString(keyword), b.scheduleExpiry(userID, cmd, keyword))
 }
 
 func (b *Handler) handleWatchlist(userID int64) string {
@@ -123,12 +269,66 @@ func (b *Handler) handleWatchlist(userID int64) string {
 		resp = append(resp, "")
 	}
 
+	for _, list := range []struct {
+		name string
+		data data.Interface
+	}{{"following", b.follows}, {"blocked", b.blocks}} {
+		authors := list.data.Get(userID)
+		if len(authors) == 0 {
+			continue
+		}
+
+		names := make([]string, 0)
+		for k := range authors {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		resp = append(resp, fmt.Sprintf("<b>%s:</b>", strings.ToUpper(list.name)))
+		for _, name := range names {
+			resp = append(resp, fmt.Sprintf(" - /u/%v <i>(%d hits)</i>", html.EscapeString(name), authors[name]))
+		}
+		resp = append(resp, "")
+	}
+
 	if len(resp) > 0 {
 		return fmt.Sprintf("These are your current watch items:\n%v", strings.Join(resp, "\n"))
 	}
 	return "There are no items on your watch list"
 }
 
+func (b *Handler) handleSynonyms() string {
+	dictionary, err := matcher.LoadDictionary(b.synonyms)
+	if err != nil {
+		b.logger.Println("Unable to reload synonyms: ", err)
+		return fmt.Sprintf("Unable to reload synonyms, keeping <b>%d</b> terms", b.dictionary.Size())
+	}
+
+	b.dictionary = dictionary
+	return fmt.Sprintf("Reloaded synonyms with <b>%d</b> terms", dictionary.Size())
+}
+
+func (b *Handler) handleUnparsed() string {
+	failed, total := b.unparsed.Rate()
+	rate := 0.0
+	if total > 0 {
+		rate = float64(failed) / float64(total) * 100
+	}
+
+	resp := []string{
+		fmt.Sprintf("<b>Unparsed Titles:</b> <i>(%d of %d posts, %.1f%%)</i>", failed, total, rate),
+	}
+	for _, entry := range b.unparsed.Recent(10) {
+		flair := ""
+		if entry.Flair != "" {
+			flair = fmt.Sprintf(" [%s]", html.EscapeString(entry.Flair))
+		}
+		resp = append(resp, fmt.Sprintf(" - %s%s <i>(%s)</i>", html.EscapeString(entry.Title), flair, entry.Time.UTC().Format("2006-01-02 15:04")))
+	}
+
+	return strings.Join(resp, "\n")
+}
+
 func (b *Handler) handleHelp() string {
 	return fmt.Sprintf(`Hi, I'm <a href="https://github.com/stjohnjohnson/reddit-watcher">reddit-watcher@%v</a>. I watch /r/mechmarket for specific keywords%s`, b.version, html.EscapeString(helpText))
 }
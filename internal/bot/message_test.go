@@ -7,9 +7,11 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stjohnjohnson/reddit-watcher/internal/data"
 	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/unparsed"
 	"github.com/stjohnjohnson/reddit-watcher/mocks"
 )
 
@@ -119,7 +121,8 @@ func TestMessageUnsubscribeFail(t *testing.T) {
 				return nil
 			},
 		},
-		data: data,
+		data:     data,
+		schedule: &mocks.Scheduler{},
 	}
 
 	err := obj.incomingMessage(1, "/selling foo")
@@ -160,7 +163,8 @@ func TestMessageUnsubscribe(t *testing.T) {
 				return nil
 			},
 		},
-		data: data,
+		data:     data,
+		schedule: &mocks.Scheduler{},
 	}
 
 	err := obj.incomingMessage(1, "/selling foo")
@@ -234,7 +238,8 @@ func TestMessageSubscribe(t *testing.T) {
 				return nil
 			},
 		},
-		data: data,
+		data:     data,
+		schedule: &mocks.Scheduler{},
 	}
 
 	err := obj.incomingMessage(1, "/selling foo")
@@ -271,7 +276,8 @@ func TestMessageSubscribeFail(t *testing.T) {
 				return nil
 			},
 		},
-		data: data,
+		data:     data,
+		schedule: &mocks.Scheduler{},
 	}
 
 	err := obj.incomingMessage(1, "/selling foo")
@@ -307,7 +313,9 @@ func TestMessageWatchlistEmpty(t *testing.T) {
 				return nil
 			},
 		},
-		data: d,
+		data:    d,
+		blocks:  &mocks.Data{},
+		follows: &mocks.Data{},
 	}
 
 	err := obj.incomingMessage(1, "/items")
@@ -342,15 +350,17 @@ func TestMessageWatchlist(t *testing.T) {
 				return nil
 			},
 		},
-		data: d,
+		data:    d,
+		blocks:  &mocks.Data{},
+		follows: &mocks.Data{},
 	}
 
-	err := obj.incomingMessage(1, "/items")
+	err := obj.incomingMessage(1, "/items list")
 
 	if !reflect.DeepEqual(err, nil) {
 		t.Errorf("Expected nil, got %q", err)
 	}
-	expected := "1/These are your current watch items:\n<b>BUYING:</b>\n - foo <i>(1 hits)</i>\n\n<b>SELLING:</b>\n - foo <i>(1 hits)</i>\n\n<b>VENDOR:</b>\n - foo <i>(1 hits)</i>\n\n<b>ARTISAN:</b>\n - foo <i>(1 hits)</i>\n\n<b>GROUPBUY:</b>\n - foo <i>(1 hits)</i>\n\n<b>INTERESTCHECK:</b>\n - foo <i>(1 hits)</i>\n\n<b>GIVEAWAY:</b>\n - foo <i>(1 hits)</i>\n"
+	expected := "1/These are your current watch items:\n<b>BUYING:</b>\n - foo <i>(1 hits)</i>\n\n<b>SELLING:</b>\n - foo <i>(1 hits)</i>\n\n<b>TRADING:</b>\n - foo <i>(1 hits)</i>\n\n<b>VENDOR:</b>\n - foo <i>(1 hits)</i>\n\n<b>ARTISAN:</b>\n - foo <i>(1 hits)</i>\n\n<b>GROUPBUY:</b>\n - foo <i>(1 hits)</i>\n\n<b>INTERESTCHECK:</b>\n - foo <i>(1 hits)</i>\n\n<b>GIVEAWAY:</b>\n - foo <i>(1 hits)</i>\n"
 	if actual != expected {
 		t.Errorf("Expected %q to equal %q", actual, expected)
 	}
@@ -385,3 +395,115 @@ func TestMessageStats(t *testing.T) {
 		t.Errorf("Expected %q to start with %q", actual, expected)
 	}
 }
+
+func TestMessageSynonymsNotAdmin(t *testing.T) {
+	var actual string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = fmt.Sprintf("%d/%s", i, s)
+				return nil
+			},
+		},
+	}
+
+	err := obj.incomingMessage(1, "/synonyms")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := "1/That command doesn't look like anything to me."
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageSynonymsFail(t *testing.T) {
+	var actual string
+	dictionary := matcher.NewDictionary(map[string][]string{
+		"hhkb": {"happy hacking keyboard"},
+	})
+	obj := &Handler{
+		logger:     log.New(ioutil.Discard, "", 0),
+		admins:     map[int64]bool{1: true},
+		synonyms:   "/tmp/foo/missing.json",
+		dictionary: dictionary,
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = fmt.Sprintf("%d/%s", i, s)
+				return nil
+			},
+		},
+	}
+
+	err := obj.incomingMessage(1, "/synonyms")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := "1/Unable to reload synonyms, keeping <b>2</b> terms"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+	if obj.dictionary != dictionary {
+		t.Errorf("Expected dictionary to be kept")
+	}
+}
+
+func TestMessageUnparsed(t *testing.T) {
+	var actual string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		admins: map[int64]bool{1: true},
+		unparsed: &mocks.Unparsed{
+			MockRate: func() (int64, int64) {
+				return 1, 8
+			},
+			MockRecent: func(i int) []unparsed.Entry {
+				return []unparsed.Entry{
+					{Title: "May <Confirmed> Trade Thread", Flair: "Meta", Time: time.Date(2018, time.May, 1, 12, 30, 0, 0, time.UTC)},
+				}
+			},
+		},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = fmt.Sprintf("%d/%s", i, s)
+				return nil
+			},
+		},
+	}
+
+	err := obj.incomingMessage(1, "/unparsed")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := "1/<b>Unparsed Titles:</b> <i>(1 of 8 posts, 12.5%)</i>\n - May &lt;Confirmed&gt; Trade Thread [Meta] <i>(2018-05-01 12:30)</i>"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageUnparsedNotAdmin(t *testing.T) {
+	var actual string
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = fmt.Sprintf("%d/%s", i, s)
+				return nil
+			},
+		},
+	}
+
+	err := obj.incomingMessage(2, "/unparsed")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := "2/That command doesn't look like anything to me."
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
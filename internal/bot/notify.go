@@ -0,0 +1,336 @@
+package bot
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"html"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/identity"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+// notifyKey prefixes the settings that hold where a chat's matches are sent
+const notifyKey = "notify."
+
+// verifyKey prefixes the settings that hold a target waiting to be confirmed
+const verifyKey = "verify."
+
+// verifyTimeout is how long a confirmation code can be used for
+const verifyTimeout = time.Hour
+
+const (
+	// maxVerifyFailures is how many wrong codes drop the target waiting to be confirmed
+	maxVerifyFailures = 3
+	// maxVerifySends is how many codes a user, or a target, can be sent within verifyTimeout
+	maxVerifySends = 3
+)
+
+// verifySends counts the codes sent since the first one
+type verifySends struct {
+	count int
+	since time.Time
+}
+
+var notifyText = `Choose where your matches are sent:
+ /notify telegram on|off - this Telegram chat
+ /notify discord <webhook url>|off - a Discord channel webhook
+ /notify slack <channel id>|off - a Slack channel the app was added to
+ /notify email <address>|off - an email address, once you confirm the code sent to it
+ /notify webhook <https url>|off - your own service, as signed JSON
+ /notify matrix <room id>|off - a Matrix room the bot was invited to
+ /notify ntfy <topic url>|off - push notifications from an ntfy topic
+ /notify gotify <server url>?token=<app token>|off - push notifications from a Gotify app`
+
+// transport returns the notifier for a transport
+// Telegram shares the bot's chat connection, the others are set up in New
+func (b *Handler) transport(name string) (notifier.Interface, bool) {
+	if name == notifier.Telegram {
+		return notifier.NewTelegram(b.chat), true
+	}
+	n, ok := b.notifiers[name]
+	return n, ok
+}
+
+// transports returns the name of every transport, Telegram first
+func (b *Handler) transports() []string {
+	names := []string{}
+	for name := range b.notifiers {
+		if name != notifier.Telegram {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{notifier.Telegram}, names...)
+}
+
+// home returns the address a user talks to the bot from
+func (b *Handler) home(userID int64) notifier.Address {
+	if userID >= identity.Offset {
+		transport, account, ok := b.identities.Lookup(userID)
+		switch {
+		case ok && transport == notifier.Slack:
+			return notifier.Address{Transport: transport, Target: slackUser(account)}
+		case ok && transport == notifier.Matrix:
+			return notifier.Address{Transport: transport, Target: account}
+		}
+	}
+	return notifier.Address{Transport: notifier.Telegram, Target: strconv.FormatInt(userID, 10)}
+}
+
+// addresses returns where a chat's matches are sent
+// The home address is on unless turned off, the others are on once they have a target
+func (b *Handler) addresses(userID int64) []notifier.Address {
+	home := b.home(userID)
+	addrs := []notifier.Address{}
+	for _, name := range b.transports() {
+		target := b.settings.Get(userID, notifyKey+name)
+		if name == home.Transport {
+			if target == "off" {
+				continue
+			}
+			target = home.Target
+		}
+		if target == "" {
+			continue
+		}
+		addrs = append(addrs, notifier.Address{Transport: name, Target: target})
+	}
+	return addrs
+}
+
+// notify sends a notification to every address of a chat, and to the global webhook
+func (b *Handler) notify(userID int64, n notifier.Notification) {
+	n.UserID = userID
+	n.Style = b.settings.Get(userID, styleKey)
+	n.App = b.settings.Get(userID, appKey)
+	n.Rich = b.settings.Get(userID, richKey) == "on"
+	addrs := b.addresses(userID)
+	if b.webhook != "" {
+		addrs = append(addrs, notifier.Address{Transport: notifier.Webhook, Target: b.webhook})
+	}
+
+	for _, addr := range addrs {
+		t, ok := b.transport(addr.Transport)
+		if !ok {
+			b.logger.Printf("Unknown transport %s for @%d", addr.Transport, userID)
+			continue
+		}
+
+		err := t.Notify(addr.Target, n)
+		if err != nil {
+			b.logger.Printf("Unable to notify @%d over %s: %s", userID, addr.Transport, err)
+		}
+	}
+}
+
+func (b *Handler) handleNotify(userID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		resp := []string{"Your matches are sent to:"}
+		for _, addr := range b.addresses(userID) {
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>", addr.Transport))
+		}
+		if len(resp) == 1 {
+			resp = []string{"Your matches aren't sent anywhere"}
+		}
+		return fmt.Sprintf("%s\n\n%s", strings.Join(resp, "\n"), html.EscapeString(notifyText))
+	}
+
+	name := strings.ToLower(fields[0])
+	t, ok := b.transport(name)
+	if !ok {
+		return fmt.Sprintf("I don't know how to send to <b>%s</b>", html.EscapeString(name))
+	}
+	if len(fields) == 3 && strings.EqualFold(fields[1], "verify") {
+		return b.finishVerify(userID, name, fields[2])
+	}
+	if len(fields) != 2 {
+		return html.EscapeString(notifyText)
+	}
+
+	value := fields[1]
+	enabled := !strings.EqualFold(value, "off")
+	home := b.home(userID).Transport
+	switch {
+	case name == home && strings.EqualFold(value, "on"):
+		value = ""
+	case name == home && !enabled:
+		value = "off"
+	case name == home:
+		return html.EscapeString(notifyText)
+	case !enabled:
+		value = ""
+	default:
+		err := t.Validate(value)
+		if err != nil {
+			return fmt.Sprintf("That doesn't look right for <b>%s</b>: %s", html.EscapeString(name), html.EscapeString(err.Error()))
+		}
+		if v, ok := t.(notifier.Verifier); ok {
+			return b.startVerify(userID, name, value, v)
+		}
+	}
+
+	resp, ok := b.saveNotify(userID, name, value, enabled)
+	if s, signed := t.(notifier.Signer); ok && signed && enabled {
+		resp = fmt.Sprintf("%s\nThey're signed with the key <code>%s</code>", resp, html.EscapeString(s.SigningKey(value)))
+	}
+	return resp
+}
+
+// saveNotify changes where a chat's matches are sent, saying whether it was saved
+func (b *Handler) saveNotify(userID int64, name, value string, enabled bool) (string, bool) {
+	err := b.settings.Set(userID, notifyKey+name, value)
+	if err != nil {
+		b.logger.Println("Unable to save setting: ", err)
+		return "Sorry, I wasn't able to save that", false
+	}
+
+	if len(b.addresses(userID)) == 0 {
+		return "Okay, but your matches aren't sent anywhere now", true
+	}
+	if !enabled {
+		return fmt.Sprintf("Okay, I've stopped sending your matches to <b>%s</b>", html.EscapeString(name)), true
+	}
+	return fmt.Sprintf("Okay, I'll send your matches to <b>%s</b>", html.EscapeString(name)), true
+}
+
+// newCode returns a random six digit confirmation code
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// startVerify sends a confirmation code to a target, which is only saved once
+// the code is sent back with /notify <transport> verify <code>
+func (b *Handler) startVerify(userID int64, name, target string, v notifier.Verifier) string {
+	if !b.allowVerify(userID, name, target) {
+		return "Too many codes have been sent, try again in an hour"
+	}
+
+	code, err := newCode()
+	if err != nil {
+		b.logger.Println("Unable to create code: ", err)
+		return "Sorry, I wasn't able to save that"
+	}
+
+	expires := time.Now().Add(verifyTimeout).Unix()
+	err = b.settings.Set(userID, verifyKey+name, fmt.Sprintf("%s %s %d 0", target, code, expires))
+	if err != nil {
+		b.logger.Println("Unable to save setting: ", err)
+		return "Sorry, I wasn't able to save that"
+	}
+
+	err = v.Verify(target, code)
+	if err != nil {
+		b.logger.Printf("Unable to send code to @%d over %s: %s", userID, name, err)
+		return fmt.Sprintf("Sorry, I wasn't able to send a code to <b>%s</b>", html.EscapeString(target))
+	}
+
+	return fmt.Sprintf("I've sent a code to <b>%s</b>, send it back with /notify %s verify &lt;code&gt; within an hour", html.EscapeString(target), html.EscapeString(name))
+}
+
+// allowVerify counts a code about to be sent, saying whether the user and the target
+// are both under maxVerifySends so the bot can't be used to flood an address
+func (b *Handler) allowVerify(userID int64, name, target string) bool {
+	if b.verifySends == nil {
+		b.verifySends = make(map[string]verifySends)
+	}
+
+	now := time.Now()
+	for key, sent := range b.verifySends {
+		if !now.Before(sent.since.Add(verifyTimeout)) {
+			delete(b.verifySends, key)
+		}
+	}
+
+	keys := []string{
+		fmt.Sprintf("user %d", userID),
+		fmt.Sprintf("target %s %s", name, strings.ToLower(target)),
+	}
+	for _, key := range keys {
+		if b.verifySends[key].count >= maxVerifySends {
+			return false
+		}
+	}
+	for _, key := range keys {
+		sent, ok := b.verifySends[key]
+		if !ok {
+			sent.since = now
+		}
+		sent.count++
+		b.verifySends[key] = sent
+	}
+	return true
+}
+
+// finishVerify saves the target waiting to be confirmed if the code matches
+// The target is dropped after maxVerifyFailures wrong codes so codes can't be guessed
+func (b *Handler) finishVerify(userID int64, name, code string) string {
+	fields := strings.Fields(b.settings.Get(userID, verifyKey+name))
+	// Targets saved before wrong codes were counted have no count
+	if len(fields) == 3 {
+		fields = append(fields, "0")
+	}
+	if len(fields) != 4 {
+		return fmt.Sprintf("There's nothing to confirm for <b>%s</b>, send /notify %s &lt;target&gt; first", html.EscapeString(name), html.EscapeString(name))
+	}
+
+	expires, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		b.settings.Set(userID, verifyKey+name, "")
+		return fmt.Sprintf("That code has expired, send /notify %s &lt;target&gt; to get a new one", html.EscapeString(name))
+	}
+	if subtle.ConstantTimeCompare([]byte(fields[1]), []byte(code)) != 1 {
+		failures, _ := strconv.Atoi(fields[3])
+		failures++
+		if failures >= maxVerifyFailures {
+			b.settings.Set(userID, verifyKey+name, "")
+			return fmt.Sprintf("That code doesn't match either, send /notify %s &lt;target&gt; to get a new one", html.EscapeString(name))
+		}
+
+		fields[3] = strconv.Itoa(failures)
+		err = b.settings.Set(userID, verifyKey+name, strings.Join(fields, " "))
+		if err != nil {
+			b.logger.Println("Unable to save setting: ", err)
+		}
+		return "That code doesn't match, check the message it was sent in"
+	}
+
+	err = b.settings.Set(userID, verifyKey+name, "")
+	if err != nil {
+		b.logger.Println("Unable to save setting: ", err)
+	}
+	resp, _ := b.saveNotify(userID, name, fields[0], true)
+	return resp
+}
+
+// incomingUnsubscribe stops sending matches to an email address from an unsubscribe link
+func (b *Handler) incomingUnsubscribe(address string) error {
+	for _, userID := range b.users() {
+		if !strings.EqualFold(b.settings.Get(userID, notifyKey+notifier.Email), address) {
+			continue
+		}
+
+		err := b.settings.Set(userID, notifyKey+notifier.Email, "")
+		if err != nil {
+			return fmt.Errorf("Unable to save setting: %v", err)
+		}
+
+		message := fmt.Sprintf("Okay, <b>%s</b> unsubscribed, so I've stopped sending your matches to <b>email</b>", html.EscapeString(address))
+		err = b.chatFor(userID).SendMessage(userID, message)
+		if err != nil {
+			b.logger.Printf("Unable to tell @%d: %s", userID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestMain(m *testing.M) {
+	err := notifier.LoadTemplates("../../templates")
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNotify(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"notify.discord": "https://discord.com/api/webhooks/1/abc",
+	})
+
+	obj.notify(1, notifier.Notification{Title: "Tada68", Reason: "matched selling tada68"})
+
+	expected := []string{
+		`msg/1/Tada68 [<a href="">web</a>] [<a href="https://git.io/vhZZN#">app</a>] <i>(matched selling tada68)</i>`,
+		"discord/https://discord.com/api/webhooks/1/abc/Tada68",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestNotifyTelegramOff(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"notify.telegram": "off",
+	})
+
+	obj.notify(1, notifier.Notification{Title: "Tada68"})
+
+	if len(actual) != 0 {
+		t.Errorf("Expected nothing to be sent, got %q", actual)
+	}
+}
+
+func TestMessageNotify(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, message := range []string{
+		"/notify discord https://example.com/hook",
+		"/notify discord https://discord.com/api/webhooks/1/abc",
+		"/notify telegram off",
+		"/notify discord off",
+		"/notify telegram on",
+		"/notify slack foo",
+	} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"msg/1/That doesn't look right for <b>discord</b>: not a Discord webhook URL",
+		"set/1/notify.discord/https://discord.com/api/webhooks/1/abc",
+		"msg/1/Okay, I'll send your matches to <b>discord</b>",
+		"set/1/notify.telegram/off",
+		"msg/1/Okay, I've stopped sending your matches to <b>telegram</b>",
+		"set/1/notify.discord/",
+		"msg/1/Okay, but your matches aren't sent anywhere now",
+		"set/1/notify.telegram/",
+		"msg/1/Okay, I'll send your matches to <b>telegram</b>",
+		"msg/1/I don't know how to send to <b>slack</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageNotifyList(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"notify.discord": "https://discord.com/api/webhooks/1/abc",
+	})
+
+	err := obj.incomingMessage(1, "/notify")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"msg/1/Your matches are sent to:\n - <b>telegram</b>\n - <b>discord</b>\n\n" + html.EscapeString(notifyText),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageNotifyVerify(t *testing.T) {
+	var actual []string
+	values := make(map[string]string)
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, values)
+
+	err := obj.incomingMessage(1, "/notify email you@example.com")
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	pending := strings.Fields(values["verify.email"])
+	if len(pending) != 4 || pending[0] != "you@example.com" || len(pending[1]) != 6 || pending[3] != "0" {
+		t.Fatalf("Expected a pending code, got %q", values["verify.email"])
+	}
+	wrong := "x" + pending[1][1:]
+
+	for _, message := range []string{
+		"/notify email verify " + wrong,
+		"/notify email verify " + pending[1],
+		"/notify email verify " + pending[1],
+	} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"set/1/verify.email/" + strings.Join(pending, " "),
+		"verify/you@example.com",
+		"msg/1/I've sent a code to <b>you@example.com</b>, send it back with /notify email verify &lt;code&gt; within an hour",
+		"set/1/verify.email/" + strings.Join(append(pending[:3:3], "1"), " "),
+		"msg/1/That code doesn't match, check the message it was sent in",
+		"set/1/verify.email/",
+		"set/1/notify.email/you@example.com",
+		"msg/1/Okay, I'll send your matches to <b>email</b>",
+		"msg/1/There's nothing to confirm for <b>email</b>, send /notify email &lt;target&gt; first",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageNotifyVerifyExpired(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"verify.email": "you@example.com 123456 1500000000",
+	})
+
+	err := obj.incomingMessage(1, "/notify email verify 123456")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"set/1/verify.email/",
+		"msg/1/That code has expired, send /notify email &lt;target&gt; to get a new one",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageNotifyVerifyLimits(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"verify.email": "you@example.com 123456 99999999999",
+	})
+
+	for _, message := range []string{
+		"/notify email verify 000000",
+		"/notify email verify 000001",
+		"/notify email verify 000002",
+		"/notify email verify 123456",
+	} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"set/1/verify.email/you@example.com 123456 99999999999 1",
+		"msg/1/That code doesn't match, check the message it was sent in",
+		"set/1/verify.email/you@example.com 123456 99999999999 2",
+		"msg/1/That code doesn't match, check the message it was sent in",
+		"set/1/verify.email/",
+		"msg/1/That code doesn't match either, send /notify email &lt;target&gt; to get a new one",
+		"msg/1/There's nothing to confirm for <b>email</b>, send /notify email &lt;target&gt; first",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	// Codes are limited per user and per target
+	sent := 0
+	obj.notifiers[notifier.Email].(*mocks.Verifier).MockVerify = func(s, code string) error {
+		sent++
+		return nil
+	}
+	for _, message := range []string{
+		"/notify email a@example.com",
+		"/notify email b@example.com",
+		"/notify email c@example.com",
+		"/notify email d@example.com",
+	} {
+		obj.incomingMessage(1, message)
+	}
+	for userID := int64(2); userID < 6; userID++ {
+		obj.incomingMessage(userID, "/notify email you@example.com")
+	}
+	if sent != 6 {
+		t.Errorf("Expected 6 codes to be sent, got %d", sent)
+	}
+}
+
+func TestIncomingUnsubscribe(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.data = map[string]data.Interface{
+		matcher.Selling: &mocks.Data{
+			MockGetUsers: func() []int64 {
+				return []int64{1, 2}
+			},
+		},
+	}
+	obj.follows = &mocks.Data{}
+	obj.settings = &mocks.Settings{
+		MockGet: func(i int64, k string) string {
+			if i == 2 && k == "notify.email" {
+				return "You@Example.com"
+			}
+			return ""
+		},
+		MockSet: func(i int64, k, v string) error {
+			actual = append(actual, fmt.Sprintf("set/%d/%s/%s", i, k, v))
+			return nil
+		},
+	}
+
+	err := obj.incomingUnsubscribe("you@example.com")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"set/2/notify.email/",
+		"msg/2/Okay, <b>you@example.com</b> unsubscribed, so I've stopped sending your matches to <b>email</b>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageNotifyWebhook(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	err := obj.incomingMessage(1, "/notify webhook https://example.com/hook")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"set/1/notify.webhook/https://example.com/hook",
+		"msg/1/Okay, I'll send your matches to <b>webhook</b>\nThey're signed with the key <code>key-for-https://example.com/hook</code>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
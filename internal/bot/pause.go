@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+)
+
+const (
+	// pauseJob suppresses all notifications for a user
+	pauseJob = "pause"
+	// snoozeJob suppresses notifications for a single keyword
+	snoozeJob = "snooze"
+)
+
+// timeFormat is how dates are shown to users
+const timeFormat = "Jan 2 15:04 MST"
+
+// paused checks if all notifications for a user are paused
+func (b *Handler) paused(userID int64) bool {
+	_, ok := b.schedule.Find(scheduler.Job{Kind: pauseJob, UserID: userID})
+	return ok
+}
+
+// suppressed checks if notifications for a keyword are paused or snoozed
+func (b *Handler) suppressed(userID int64, cmd, keyword string) bool {
+	if b.paused(userID) {
+		return true
+	}
+
+	_, ok := b.schedule.Find(scheduler.Job{Kind: snoozeJob, UserID: userID, Type: cmd, Keyword: keyword})
+	return ok
+}
+
+func (b *Handler) handlePause(userID int64, arg string) string {
+	job := scheduler.Job{Kind: pauseJob, UserID: userID}
+	resp := "Okay, I've paused all notifications. Send /resume to start them again"
+
+	if arg != "" {
+		duration, err := scheduler.ParseDuration(arg)
+		if err != nil {
+			return "How long should I pause for? (e.g. /pause 3d)"
+		}
+		job.At = time.Now().Add(duration)
+		resp = fmt.Sprintf("Okay, I've paused all notifications until <b>%s</b>. Send /resume to start them sooner", job.At.UTC().Format(timeFormat))
+	}
+
+	err := b.schedule.Add(job)
+	if err != nil {
+		b.logger.Println("Unable to pause: ", err)
+	}
+
+	return resp
+}
+
+func (b *Handler) handleResume(userID int64) string {
+	if !b.paused(userID) {
+		return "Notifications aren't paused"
+	}
+
+	err := b.schedule.Remove(scheduler.Job{Kind: pauseJob, UserID: userID})
+	if err != nil {
+		b.logger.Println("Unable to resume: ", err)
+	}
+
+	return "Welcome back, notifications are on again"
+}
+
+func (b *Handler) handleSnooze(userID int64, arg string) string {
+	usage := "What should I snooze? (e.g. /snooze selling tada68 3d)"
+
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return usage
+	}
+	cmd, keyword := strings.ToLower(fields[0]), strings.ToLower(strings.Join(fields[1:len(fields)-1], " "))
+	if keyword == "" {
+		keyword = "*"
+	}
+
+	duration, err := scheduler.ParseDuration(fields[len(fields)-1])
+	if err != nil {
+		return usage
+	}
+
+	d, ok := b.data[cmd]
+	if !ok || !d.Exists(userID, keyword) {
+		return fmt.Sprintf("I'm not watching for <b>%s</b> posts that match <b>%s</b>", html.EscapeString(cmd), html.EscapeString(keyword))
+	}
+
+	job := scheduler.Job{
+		Kind:    snoozeJob,
+		UserID:  userID,
+		Type:    cmd,
+		Keyword: keyword,
+		At:      time.Now().Add(duration),
+	}
+	err = b.schedule.Add(job)
+	if err != nil {
+		b.logger.Println("Unable to snooze: ", err)
+	}
+
+	return fmt.Sprintf("Okay, I've snoozed <b>%s</b> posts that match <b>%s</b> until <b>%s</b>", html.EscapeString(cmd), html.EscapeString(keyword), job.At.UTC().Format(timeFormat))
+}
+
+// incomingJob lets the user know when a pause, snooze, or watch is over
+func (b *Handler) incomingJob(job scheduler.Job) error {
+	var resp string
+
+	switch job.Kind {
+	case pauseJob:
+		resp = "Your pause is over, notifications are on again"
+
+	case snoozeJob:
+		d, ok := b.data[job.Type]
+		if !ok || !d.Exists(job.UserID, job.Keyword) {
+			return nil
+		}
+		resp = fmt.Sprintf("Your snooze is over, I'm watching for <b>%s</b> posts that match <b>%s</b> again", html.EscapeString(job.Type), html.EscapeString(job.Keyword))
+
+	case expireJob:
+		return b.endWatch(job.UserID, job.Type, job.Keyword, "Time's up")
+
+	default:
+		return fmt.Errorf("unknown job: %s", job.Kind)
+	}
+
+	err := b.chatFor(job.UserID).SendMessage(job.UserID, resp)
+	if err != nil {
+		return fmt.Errorf("Unable to send message: %v", err)
+	}
+
+	return nil
+}
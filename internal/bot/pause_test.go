@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestMessagePause(t *testing.T) {
+	var actual []string
+	var jobs []scheduler.Job
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		schedule: &mocks.Scheduler{
+			MockAdd: func(j scheduler.Job) error {
+				jobs = append(jobs, j)
+				return nil
+			},
+		},
+	}
+
+	for _, msg := range []string{"/pause", "/pause 3d", "/pause forever"} {
+		err := obj.incomingMessage(1, msg)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	if len(jobs) != 2 || !jobs[0].At.IsZero() || jobs[0].Kind != pauseJob {
+		t.Fatalf("Expected an indefinite and a timed pause, got %+v", jobs)
+	}
+	if d := time.Until(jobs[1].At); d < 71*time.Hour || d > 72*time.Hour {
+		t.Errorf("Expected pause for 3 days, got %v", d)
+	}
+
+	if actual[0] != "Okay, I've paused all notifications. Send /resume to start them again" {
+		t.Errorf("Unexpected response %q", actual[0])
+	}
+	if !strings.HasPrefix(actual[1], "Okay, I've paused all notifications until <b>") {
+		t.Errorf("Unexpected response %q", actual[1])
+	}
+	if actual[2] != "How long should I pause for? (e.g. /pause 3d)" {
+		t.Errorf("Unexpected response %q", actual[2])
+	}
+}
+
+func TestMessageResume(t *testing.T) {
+	var actual []string
+	paused := true
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		schedule: &mocks.Scheduler{
+			MockFind: func(j scheduler.Job) (scheduler.Job, bool) {
+				return j, paused
+			},
+			MockRemove: func(j scheduler.Job) error {
+				paused = false
+				return nil
+			},
+		},
+	}
+
+	obj.incomingMessage(1, "/resume")
+	obj.incomingMessage(1, "/resume")
+
+	expected := []string{
+		"Welcome back, notifications are on again",
+		"Notifications aren't paused",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageSnooze(t *testing.T) {
+	var actual []string
+	var jobs []scheduler.Job
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "gmk olivia" || s == "*"
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		schedule: &mocks.Scheduler{
+			MockAdd: func(j scheduler.Job) error {
+				jobs = append(jobs, j)
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	for _, msg := range []string{"/snooze selling GMK Olivia 12h", "/snooze selling 1w", "/snooze selling tada68 1d", "/snooze vendor foo 1d", "/snooze selling foo", "/snooze"} {
+		err := obj.incomingMessage(1, msg)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("Expected two snoozes, got %+v", jobs)
+	}
+	if jobs[0].Kind != snoozeJob || jobs[0].Type != matcher.Selling || jobs[0].Keyword != "gmk olivia" {
+		t.Errorf("Unexpected job %+v", jobs[0])
+	}
+	if jobs[1].Keyword != "*" {
+		t.Errorf("Unexpected job %+v", jobs[1])
+	}
+
+	if !strings.HasPrefix(actual[0], "Okay, I've snoozed <b>selling</b> posts that match <b>gmk olivia</b> until <b>") {
+		t.Errorf("Unexpected response %q", actual[0])
+	}
+	expected := []string{
+		"I'm not watching for <b>selling</b> posts that match <b>tada68</b>",
+		"I'm not watching for <b>vendor</b> posts that match <b>foo</b>",
+		"What should I snooze? (e.g. /snooze selling tada68 3d)",
+		"What should I snooze? (e.g. /snooze selling tada68 3d)",
+	}
+	if !reflect.DeepEqual(actual[2:], expected) {
+		t.Errorf("Expected %q to equal %q", actual[2:], expected)
+	}
+}
+
+func TestJobExpired(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	d[matcher.Selling] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "tada68"
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("%d/%s", i, s))
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	jobs := []scheduler.Job{
+		{Kind: pauseJob, UserID: 1},
+		{Kind: snoozeJob, UserID: 2, Type: matcher.Selling, Keyword: "tada68"},
+		{Kind: snoozeJob, UserID: 3, Type: matcher.Selling, Keyword: "removed"},
+	}
+	for _, job := range jobs {
+		err := obj.incomingJob(job)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"1/Your pause is over, notifications are on again",
+		"2/Your snooze is over, I'm watching for <b>selling</b> posts that match <b>tada68</b> again",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	err := obj.incomingJob(scheduler.Job{Kind: "unknown"})
+	if !reflect.DeepEqual(err, fmt.Errorf("unknown job: unknown")) {
+		t.Errorf("Expected unknown job error, got %q", err)
+	}
+}
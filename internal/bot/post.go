@@ -2,41 +2,149 @@ package bot
 
 import (
 	"fmt"
-	"html"
 	"regexp"
+	"time"
 
 	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
 	"github.com/turnage/graw/reddit"
 )
 
-var messageTemplate = `%s [<a href="%s">web</a>] [<a href="https://git.io/vhZZN#%s">app</a>] <i>(matched %s %s)</i>`
+// excerptLength is the most of a post's body shown in detailed and rich notifications
+const excerptLength = 200
+
+// newNotification describes a post with the given words highlighted and the reason it was sent
+// The item is nil when the post couldn't be classified
+func newNotification(post *reddit.Post, item *matcher.ParsedPost, highlight *regexp.Regexp, reason string) notifier.Notification {
+	if item == nil {
+		item = &matcher.ParsedPost{}
+	}
+	trades, ok := matcher.ParseReputation(post.AuthorFlairText)
+	body := matcher.ParseSelfText(post.SelfText)
+	return notifier.Notification{
+		Title:     post.Title,
+		URL:       post.URL,
+		Permalink: post.Permalink,
+		Author:    post.Author,
+		Trades:    trades,
+		HasTrades: ok,
+		Highlight: highlight,
+		Reason:    reason,
+		Type:      item.Type,
+		Region:    item.Region,
+		Have:      item.Have,
+		Want:      item.Want,
+		Price:     body.Price(),
+		Excerpt:   body.ExcerptAround(highlight, excerptLength),
+		Image:     body.Image(),
+	}
+}
 
 func (b *Handler) incomingPost(post *reddit.Post) error {
-	item, err := matcher.ParseTitle(post.Title)
+	b.lastPost = time.Now()
+
+	// Keep track of titles the parser can't handle
+	_, err := matcher.ParseTitle(post.Title)
+	if err := b.unparsed.Record(post.Title, post.LinkFlairText, err == nil); err != nil {
+		b.logger.Printf("Unable to record unparsed title: %s", err)
+	}
+
+	item, err := matcher.Classify(post.Title, post.LinkFlairText)
 	if err != nil {
+		// Followed authors are notified of every post, even ones that can't be classified
+		b.matchAuthor(post, nil, make(map[int64]bool))
 		return fmt.Errorf("unable to parse title: %s", err)
 	}
-	b.logger.Printf("PARSE: type: %s region: %s", item.Type, item.Region)
+	b.logger.Printf("PARSE: type: %s region: %s flair: %s", item.Type, item.Region, post.LinkFlairText)
 
 	// Record stats for type
 	// @TODO Record stats for region
 	b.stats.Increment(item.Type)
 
-	d, ok := b.data[item.Type]
+	// Record where the title parser and flair don't line up
+	if item.Fallback {
+		b.logger.Printf("FLAIR: classified by flair %q: %s", post.LinkFlairText, post.Title)
+		b.stats.Increment("flair fallback")
+	} else if item.Disagrees() {
+		b.logger.Printf("FLAIR: title says %s, flair says %s: %s", item.Type, item.Flair, post.Title)
+		b.stats.Increment("flair mismatch")
+	}
+
+	// Keep the first error so followers are still notified
+	var targetErr error
+	notified := make(map[int64]bool)
+	for _, target := range item.Targets() {
+		err = b.matchTarget(post, item, target, notified)
+		if err != nil && targetErr == nil {
+			targetErr = err
+		}
+	}
+
+	b.matchAuthor(post, item, notified)
+
+	return targetErr
+}
+
+// matchAuthor notifies the followers of the post author that haven't already been notified
+// The item is nil when the post couldn't be classified
+func (b *Handler) matchAuthor(post *reddit.Post, item *matcher.ParsedPost, notified map[int64]bool) {
+	if post.Author == "" {
+		return
+	}
+
+	message := newNotification(post, item, regexp.MustCompile(`(?i)(\[[^\]]+\])`), fmt.Sprintf("followed /u/%s", post.Author))
+	for _, id := range b.follows.GetByKeyword(post.Author) {
+		if notified[id] || b.blocks.Exists(id, post.Author) || b.paused(id) || b.banned(id) {
+			continue
+		}
+		b.logger.Printf("FOLLOW: /u/%s for @%d, %s", post.Author, id, post.URL)
+
+		b.notify(id, message)
+		notified[id] = true
+
+		err := b.follows.Increment(id, post.Author)
+		if err != nil {
+			b.logger.Printf("Unable to increment counter: %s", err)
+		}
+	}
+}
+
+// matchTarget notifies the subscribers of a single type that match the post
+func (b *Handler) matchTarget(post *reddit.Post, item *matcher.ParsedPost, target matcher.Target, notified map[int64]bool) error {
+	d, ok := b.data[target.Type]
 	if !ok {
-		return fmt.Errorf("unknown type: %s", item.Type)
+		return fmt.Errorf("unknown type: %s", target.Type)
+	}
+
+	// Skip subscriptions that don't include trades or need a better reputation
+	trades, _ := matcher.ParseReputation(post.AuthorFlairText)
+	keywords := []string{}
+	for _, keyword := range d.GetKeywords() {
+		sub := matcher.ParseSubscription(keyword)
+		if target.Trades && !sub.Trades {
+			continue
+		}
+		if sub.MinRep > trades {
+			continue
+		}
+		keywords = append(keywords, keyword)
 	}
 
-	keywords := matcher.FindMatching(d.GetKeywords(), item.Contents, post.SelfText)
-	for _, keyword := range keywords {
-		escapedKeyword := html.EscapeString(keyword)
-		escapedTitle := html.EscapeString(post.Title)
+	matches := matcher.FindMatching(b.dictionary, keywords, target.Contents, post.SelfText)
+	for _, match := range matches {
+		keyword := match.Keyword
+		reason := keyword
 		keywordReplacer := regexp.MustCompile(`(?i)(\[[^\]]+\])`)
-		if keyword != "*" {
-			keywordReplacer = regexp.MustCompile("(?i)(" + regexp.QuoteMeta(escapedKeyword) + ")")
+		if match.Term != "*" {
+			keywordReplacer = regexp.MustCompile("(?i)(" + regexp.QuoteMeta(match.Term) + ")")
 		}
-		escapedTitle = keywordReplacer.ReplaceAllString(escapedTitle, "<b>$1</b>")
-		message := fmt.Sprintf(messageTemplate, escapedTitle, post.URL, post.Permalink, item.Type, escapedKeyword)
+		// Mention the canonical term when the keyword is an alias
+		if match.Canonical != matcher.ParseSubscription(keyword).Keyword {
+			reason = fmt.Sprintf("%s as %s", reason, match.Canonical)
+		}
+		message := newNotification(post, item, keywordReplacer, fmt.Sprintf("matched %s %s", target.Type, reason))
+		message.Type = target.Type
+		message.Keywords = []string{keyword}
 
 		ids := d.GetByKeyword(keyword)
 		for _, id := range ids {
@@ -44,17 +152,29 @@ func (b *Handler) incomingPost(post *reddit.Post) error {
 			if item.Region != "US" && item.Region != "" {
 				continue
 			}
-			b.logger.Printf("MATCH: %s/%s for @%d, %s", item.Type, keyword, id, post.URL)
-
-			err = b.chat.SendMessage(id, message)
-			if err != nil {
-				b.logger.Printf("Unable to send message: %s", err)
+			if b.blocks.Exists(id, post.Author) || b.banned(id) {
+				continue
 			}
+			if b.suppressed(id, target.Type, keyword) {
+				b.logger.Printf("SNOOZED: %s/%s for @%d", target.Type, keyword, id)
+				continue
+			}
+			b.logger.Printf("MATCH: %s/%s for @%d, %s", target.Type, keyword, id, post.URL)
+
+			b.notify(id, message)
+			notified[id] = true
 
-			err = d.Increment(id, keyword)
+			err := d.Increment(id, keyword)
 			if err != nil {
 				b.logger.Printf("Unable to increment counter: %s", err)
 			}
+
+			if matcher.ParseSubscription(keyword).Once {
+				err = b.endWatch(id, target.Type, keyword, "That was your first match")
+				if err != nil {
+					b.logger.Printf("Unable to end watch: %s", err)
+				}
+			}
 		}
 	}
 
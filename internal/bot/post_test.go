@@ -9,13 +9,21 @@ import (
 
 	"github.com/stjohnjohnson/reddit-watcher/internal/data"
 	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
 	"github.com/stjohnjohnson/reddit-watcher/mocks"
 	"github.com/turnage/graw/reddit"
 )
 
 func TestBadPost(t *testing.T) {
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
 	}
 
 	expected := fmt.Errorf("unable to parse title: not parsable: ")
@@ -26,11 +34,44 @@ func TestBadPost(t *testing.T) {
 	}
 }
 
-func TestBadType(t *testing.T) {
+func TestRecordUnparsed(t *testing.T) {
+	var actual []string
 	obj := &Handler{
 		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
-		data:   make(map[string]data.Interface),
+		unparsed: &mocks.Unparsed{
+			MockRecord: func(title, flair string, parsed bool) error {
+				actual = append(actual, fmt.Sprintf("%s/%s/%v", title, flair, parsed))
+				return fmt.Errorf("disk full")
+			},
+		},
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:         "May Confirmed Trade Thread",
+		LinkFlairText: "Meta",
+	})
+
+	expectedErr := fmt.Errorf("unable to parse title: not parsable: May Confirmed Trade Thread")
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected %q, got %q", expectedErr, err)
+	}
+	expected := []string{"May Confirmed Trade Thread/Meta/false"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestBadType(t *testing.T) {
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		data:     make(map[string]data.Interface),
 	}
 
 	expected := fmt.Errorf("unknown type: buying")
@@ -54,8 +95,14 @@ func TestNoMatch(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				t.Errorf("Unexpected call to SendMessage %d, %s", i, s)
@@ -85,8 +132,14 @@ func TestBadRegion(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				t.Errorf("Unexpected call to SendMessage %d, %s", i, s)
@@ -117,8 +170,14 @@ func TestBadMessage(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				actual = s
@@ -159,8 +218,14 @@ func TestBadHitIncrement(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				actual = s
@@ -201,8 +266,14 @@ func TestHit(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				actual = s
@@ -243,8 +314,14 @@ func TestHitStar(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				actual = s
@@ -285,8 +362,14 @@ func TestHitStarVendor(t *testing.T) {
 		},
 	}
 	obj := &Handler{
-		logger: log.New(ioutil.Discard, "", 0),
-		stats:  &mocks.Stats{},
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
 		chat: &mocks.Chatter{
 			MockSendMessage: func(i int64, s string) error {
 				actual = s
@@ -311,3 +394,546 @@ func TestHitStarVendor(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, actual)
 	}
 }
+
+func TestHitAlias(t *testing.T) {
+	var actual string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"zeal"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		dictionary: matcher.NewDictionary(map[string][]string{
+			"zealios": {"zeal", "zealpc zealios"},
+		}),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = s
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:     "[US-CA] [H] Zealios 67g [W] PayPal",
+		Permalink: "/r/foo",
+		URL:       "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := "[US-CA] [H] <b>Zealios</b> 67g [W] PayPal [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling zeal as zealios)</i>"
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestHitTrade(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Trading] = &mocks.Data{}
+	data[matcher.Buying] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68 trades"}
+		},
+	}
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68", "tada68 trades"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:     "[US-NY] [H] Tada68 [W] HHKB",
+		Permalink: "/r/foo",
+		URL:       "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"[US-NY] [H] <b>Tada68</b> [W] HHKB [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling tada68 trades)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestHitTradeBuying(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Trading] = &mocks.Data{}
+	data[matcher.Selling] = &mocks.Data{}
+	data[matcher.Buying] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"hhkb"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:     "[US-NY] [H] Tada68 [W] HHKB",
+		Permalink: "/r/foo",
+		URL:       "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"[US-NY] [H] Tada68 [W] <b>HHKB</b> [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched buying hhkb)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestFlairMismatch(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats: &mocks.Stats{
+			MockIncrement: func(s string) {
+				actual = append(actual, s)
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:         "[US-CA] [H] Tada68 [W] PayPal",
+		LinkFlairText: "Buying",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{"selling", "flair mismatch"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestFlairFallback(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats: &mocks.Stats{
+			MockIncrement: func(s string) {
+				actual = append(actual, s)
+			},
+		},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:         "[US-CA] Tada68 for sale",
+		LinkFlairText: "Selling",
+		Permalink:     "/r/foo",
+		URL:           "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"selling",
+		"flair fallback",
+		"[US-CA] <b>Tada68</b> for sale [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling tada68)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestBlockedAuthor(t *testing.T) {
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		blocks: &mocks.Data{
+			MockExists: func(i int64, s string) bool {
+				return s == "flaky"
+			},
+		},
+		follows: &mocks.Data{
+			MockGetByKeyword: func(s string) []int64 {
+				return []int64{1}
+			},
+		},
+		stats: &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				t.Errorf("Unexpected call to SendMessage %d, %s", i, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:  "[US-CA] [H] Tada68 [W] PayPal",
+		Author: "flaky",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+}
+
+func TestFollowedAuthor(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows: &mocks.Data{
+			MockGetByKeyword: func(s string) []int64 {
+				return []int64{1, 2}
+			},
+			MockIncrement: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("inc/%d/%s", i, s))
+				return nil
+			},
+		},
+		stats: &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		Author:    "trusted",
+		Permalink: "/r/foo",
+		URL:       "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"msg/1/[US-CA] [H] <b>Tada68</b> [W] PayPal by /u/trusted [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling tada68)</i>",
+		"msg/2/<b>[US-CA]</b> <b>[H]</b> Tada68 <b>[W]</b> PayPal by /u/trusted [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(followed /u/trusted)</i>",
+		"inc/2/trusted",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestFollowedAuthorUnparsable(t *testing.T) {
+	var actual []string
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows: &mocks.Data{
+			MockGetByKeyword: func(s string) []int64 {
+				return []int64{2}
+			},
+		},
+		stats: &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data: make(map[string]data.Interface),
+	}
+
+	for _, title := range []string{"Keycap collection pics", "[US-CA] [H] Money [W] Tada68"} {
+		err := obj.incomingPost(&reddit.Post{
+			Title:     title,
+			Author:    "trusted",
+			Permalink: "/r/foo",
+			URL:       "https://r.com/r/foobar",
+		})
+		if err == nil {
+			t.Errorf("Expected an error for %q", title)
+		}
+	}
+
+	expected := []string{
+		"msg/2/Keycap collection pics by /u/trusted [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(followed /u/trusted)</i>",
+		"msg/2/<b>[US-CA]</b> <b>[H]</b> Money <b>[W]</b> Tada68 by /u/trusted [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(followed /u/trusted)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestMinReputation(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"gmk minrep:10", "gmk minrep:50"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:           "[US-CA] [H] GMK Olivia [W] PayPal",
+		Author:          "seller",
+		AuthorFlairText: "Trades: 42",
+		Permalink:       "/r/foo",
+		URL:             "https://r.com/r/foobar",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"[US-CA] [H] <b>GMK</b> Olivia [W] PayPal by /u/seller (42 trades) [<a href=\"https://r.com/r/foobar\">web</a>] [<a href=\"https://git.io/vhZZN#/r/foo\">app</a>] <i>(matched selling gmk minrep:10)</i>",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestSnoozed(t *testing.T) {
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{
+			MockFind: func(j scheduler.Job) (scheduler.Job, bool) {
+				return j, j.Kind == snoozeJob && j.Keyword == "tada68"
+			},
+		},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				t.Errorf("Unexpected call to SendMessage %d, %s", i, s)
+				return nil
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title: "[US-CA] [H] Tada68 [W] PayPal",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+}
+
+func TestHitWebhook(t *testing.T) {
+	var actual []notifier.Notification
+	data := make(map[string]data.Interface)
+	data[matcher.Buying] = &mocks.Data{
+		MockGetKeywords: func() []string {
+			return []string{"tada68"}
+		},
+		MockGetByKeyword: func(s string) []int64 {
+			return []int64{1}
+		},
+		MockIncrement: func(int64, string) error {
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger:   log.New(ioutil.Discard, "", 0),
+		unparsed: &mocks.Unparsed{},
+		schedule: &mocks.Scheduler{},
+		blocks:   &mocks.Data{},
+		bans:     &mocks.Data{},
+		settings: &mocks.Settings{},
+		follows:  &mocks.Data{},
+		stats:    &mocks.Stats{},
+		chat:     &mocks.Chatter{},
+		webhook:  "https://hooks.example.com/all",
+		notifiers: map[string]notifier.Interface{
+			notifier.Webhook: &mocks.Notifier{
+				MockNotify: func(s string, n notifier.Notification) error {
+					n.Highlight = nil
+					actual = append(actual, n)
+					return nil
+				},
+			},
+		},
+		data: data,
+	}
+
+	err := obj.incomingPost(&reddit.Post{
+		Title:           "[US-CA] [H] Money [W] Tada68",
+		Permalink:       "/r/foo",
+		URL:             "https://r.com/r/foobar",
+		Author:          "bob",
+		AuthorFlairText: "Trades: 4",
+		SelfText:        "Timestamps: https://imgur.com/AbCdE1\n\nLooking for a Tada68, paying $100 shipped",
+	})
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []notifier.Notification{{
+		Title:     "[US-CA] [H] Money [W] Tada68",
+		URL:       "https://r.com/r/foobar",
+		Permalink: "/r/foo",
+		Author:    "bob",
+		Trades:    4,
+		HasTrades: true,
+		Reason:    "matched buying tada68",
+		Type:      matcher.Buying,
+		Keywords:  []string{"tada68"},
+		Region:    "US",
+		Have:      "Money",
+		Want:      "Tada68",
+		Price:     "$100",
+		Excerpt:   "Looking for a Tada68, paying $100 shipped",
+		Image:     "https://i.imgur.com/AbCdE1.jpg",
+		UserID:    1,
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+)
+
+// slackPrefix namespaces the bot's slash commands in a workspace (e.g. /mm-selling)
+const slackPrefix = "mm-"
+
+// replier sends the bot's own messages, like replies and reminders, to a user
+type replier interface {
+	SendMessage(int64, string) error
+	SendButtons(int64, string, []chatter.Button) error
+	SendKeyboard(int64, string, [][]chatter.Button) error
+	SendDocument(int64, string, []byte, string) error
+}
+
+// slackReplier sends the bot's own messages to a Slack user as direct messages
+type slackReplier struct {
+	app  slack.Interface
+	user string
+}
+
+// SendMessage sends a direct message
+func (r slackReplier) SendMessage(_ int64, message string) error {
+	return r.app.PostMessage(r.user, slack.Mrkdwn(message), nil)
+}
+
+// SendButtons sends a direct message with a row of buttons
+func (r slackReplier) SendButtons(userID int64, message string, buttons []chatter.Button) error {
+	return r.SendKeyboard(userID, message, [][]chatter.Button{buttons})
+}
+
+// SendKeyboard sends a direct message with rows of buttons
+func (r slackReplier) SendKeyboard(_ int64, message string, rows [][]chatter.Button) error {
+	text := slack.Mrkdwn(message)
+	return r.app.PostMessage(r.user, text, slackBlocks(text, rows))
+}
+
+// SendDocument sends the file as a code block, since the bot can't upload files
+func (r slackReplier) SendDocument(_ int64, name string, contents []byte, caption string) error {
+	return r.app.PostMessage(r.user, fmt.Sprintf("%s\n*%s*\n```%s```", slack.Escape(caption), slack.Escape(name), slack.Escape(string(contents))), nil)
+}
+
+// slackBlocks lays out a message with a block of buttons for each row
+func slackBlocks(text string, rows [][]chatter.Button) []slack.Block {
+	blocks := []slack.Block{slack.Section(text)}
+	for i, buttons := range rows {
+		elements := []slack.Element{}
+		for j, button := range buttons {
+			elements = append(elements, slack.Button(fmt.Sprintf("button-%d-%d", i, j), button.Text, button.Data))
+		}
+		blocks = append(blocks, slack.Actions(elements...))
+	}
+	return blocks
+}
+
+// slackUser returns the user ID from a Slack account, which direct messages are sent to
+func slackUser(account string) string {
+	parts := strings.SplitN(account, "/", 2)
+	return parts[len(parts)-1]
+}
+
+// chatFor returns where the bot's own messages to a user are sent, which is
+// wherever they last talked to the bot from
+func (b *Handler) chatFor(userID int64) replier {
+	addr := b.origin(userID)
+	switch {
+	case addr.Transport == notifier.Slack && b.slack != nil:
+		return slackReplier{app: b.slack, user: addr.Target}
+	case addr.Transport == notifier.Matrix && b.matrix != nil:
+		return matrixReplier{app: b.matrix, room: addr.Target}
+	case addr.Transport == notifier.Telegram && addr.Target != strconv.FormatInt(userID, 10):
+		chatID, err := strconv.ParseInt(addr.Target, 10, 64)
+		if err == nil {
+			return telegramReplier{chat: b.chat, chatID: chatID}
+		}
+	}
+	return b.chat
+}
+
+// slackCommand turns a slash command into a bot command
+// Both /mm-selling tada68 and /mm selling tada68 are understood
+func slackCommand(command, text string) (string, string) {
+	name := strings.ToLower(strings.TrimPrefix(command, "/"))
+	if name != strings.TrimSuffix(slackPrefix, "-") {
+		return strings.TrimPrefix(name, slackPrefix), strings.TrimSpace(text)
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(text), " ", 2)
+	if len(fields) == 1 {
+		return strings.ToLower(fields[0]), ""
+	}
+	return strings.ToLower(fields[0]), strings.TrimSpace(fields[1])
+}
+
+// incomingSlack handles slash commands and button presses from Slack
+// Slack users get their own watcher user ID above identity.Offset, unless linked
+func (b *Handler) incomingSlack(event slack.Event) error {
+	userID, err := b.identities.Resolve(notifier.Slack, event.Account())
+	if err != nil {
+		b.logger.Printf("Unable to save identity: %s", err)
+	}
+	if b.banned(userID) {
+		return nil
+	}
+	b.heard(userID, notifier.Address{Transport: notifier.Slack, Target: slackUser(event.Account())})
+
+	var resp string
+	replace := event.Command == ""
+	if replace && isItems(event.Action) {
+		text, rows := b.runItems(userID, event.Action)
+		mrkdwn := slack.Mrkdwn(text)
+		err = b.slack.Respond(event.ResponseURL, mrkdwn, slackBlocks(mrkdwn, rows), true)
+		if err != nil {
+			return fmt.Errorf("Unable to respond: %v", err)
+		}
+		return nil
+	}
+	if replace {
+		resp = b.runCallback(userID, event.Action)
+	} else {
+		cmd, args := slackCommand(event.Command, event.Text)
+		if cmd == "" {
+			cmd = "help"
+		}
+		resp = b.runCommand(userID, cmd, args)
+	}
+
+	// Some commands reply on their own
+	if resp == "" {
+		return nil
+	}
+
+	err = b.slack.Respond(event.ResponseURL, slack.Mrkdwn(resp), nil, replace)
+	if err != nil {
+		return fmt.Errorf("Unable to respond: %v", err)
+	}
+	return nil
+}
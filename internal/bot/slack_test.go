@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+)
+
+func TestSlackCommand(t *testing.T) {
+	for _, test := range []struct {
+		command, text, cmd, args string
+	}{
+		{"/mm-selling", " tada68 ", "selling", "tada68"},
+		{"/MM-Items", "", "items", ""},
+		{"/mm", "Selling tada68, tofu", "selling", "tada68, tofu"},
+		{"/mm", "help", "help", ""},
+		{"/mm", "", "", ""},
+	} {
+		cmd, args := slackCommand(test.command, test.text)
+		if cmd != test.cmd || args != test.args {
+			t.Errorf("Expected %q %q to equal %q %q", cmd, args, test.cmd, test.args)
+		}
+	}
+}
+
+func TestIncomingSlack(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, event := range []slack.Event{
+		{Team: "T1", User: "U1", Command: "/mm-selling", Text: "foo", ResponseURL: "r1"},
+		{Team: "T1", User: "U2", Command: "/mm-selling", Text: "foo", ResponseURL: "r2"},
+		{Team: "T1", User: "U1", Command: "/mm", Text: "clear selling", ResponseURL: "r3"},
+		{Team: "T1", User: "U1", Action: "clear selling", ResponseURL: "r4"},
+	} {
+		err := obj.incomingSlack(event)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		fmt.Sprintf("add/%d/foo", slackID),
+		"respond/r1/Okay, I'm going to watch for *selling* posts that match *foo*/false",
+		"post/U1/Are you sure you want to stop watching *1* items (selling)?/2",
+		fmt.Sprintf("rm/%d/tada68", slackID),
+		"respond/r4/Okay, I'm no longer watching for *1* items/true",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestSlackHome(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for id, expected := range map[int64]notifier.Address{
+		1:       {Transport: notifier.Telegram, Target: "1"},
+		slackID: {Transport: notifier.Slack, Target: "U1"},
+	} {
+		if home := obj.home(id); !reflect.DeepEqual(home, expected) {
+			t.Errorf("Expected %+v to equal %+v", home, expected)
+		}
+	}
+
+	if _, ok := obj.chatFor(slackID).(slackReplier); !ok {
+		t.Errorf("Expected Slack users to be sent direct messages")
+	}
+	if _, ok := obj.chatFor(1).(slackReplier); ok {
+		t.Errorf("Expected Telegram users to be sent Telegram messages")
+	}
+}
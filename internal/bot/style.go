@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+// styleKey is the setting that holds how a chat's matches look
+const styleKey = "style"
+
+// appKey is the setting that holds which app a chat's app links open
+const appKey = "app"
+
+// richKey is the setting that turns on rich notifications
+const richKey = "rich"
+
+var styleText = `Choose how your matches look:
+ /style compact - the title, author and links on one line
+ /style detailed - adds the price and the part of the post that matched
+ /style link - just the title, linking to the post`
+
+var appText = `Choose which app the app link opens:
+ /app narwhal - Narwhal
+ /app apollo - Apollo
+ /app old - old.reddit.com
+ /app new - www.reddit.com`
+
+var richText = `Choose whether your matches are rich:
+ /rich on - adds the part of the post that matched, and the timestamp photo on Telegram
+ /rich off - just the text`
+
+func (b *Handler) handleStyle(userID int64, args string) string {
+	return b.choose(userID, styleKey, "style", args, notifier.Styles, styleText)
+}
+
+func (b *Handler) handleApp(userID int64, args string) string {
+	return b.choose(userID, appKey, "app", args, notifier.Apps, appText)
+}
+
+func (b *Handler) handleRich(userID int64, args string) string {
+	return b.choose(userID, richKey, "rich mode", args, []string{"off", "on"}, richText)
+}
+
+// choose saves one of a list of choices, the first being the default, or
+// shows the current choice when there isn't one
+func (b *Handler) choose(userID int64, key, name, args string, choices []string, text string) string {
+	current := b.settings.Get(userID, key)
+	if current == "" {
+		current = choices[0]
+	}
+
+	choice := strings.ToLower(strings.TrimSpace(args))
+	valid := false
+	for _, c := range choices {
+		valid = valid || choice == c
+	}
+	if !valid {
+		return fmt.Sprintf("Your %s is <b>%s</b>\n\n%s", name, html.EscapeString(current), html.EscapeString(text))
+	}
+
+	// The default isn't saved, so it can change later
+	value := choice
+	if choice == choices[0] {
+		value = ""
+	}
+	err := b.settings.Set(userID, key, value)
+	if err != nil {
+		b.logger.Println("Unable to save setting: ", err)
+		return "Sorry, I wasn't able to save that"
+	}
+
+	return fmt.Sprintf("Okay, your %s is now <b>%s</b>", name, html.EscapeString(choice))
+}
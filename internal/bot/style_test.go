@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+)
+
+func TestHandleStyle(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+
+	for _, test := range []struct {
+		cmd, args, expected string
+	}{
+		{"style", "", "Your style is <b>compact</b>\n\n" + styleText},
+		{"style", " Detailed ", "Okay, your style is now <b>detailed</b>"},
+		{"style", "fancy", "Your style is <b>detailed</b>\n\n" + styleText},
+		{"style", "compact", "Okay, your style is now <b>compact</b>"},
+		{"app", "old", "Okay, your app is now <b>old</b>"},
+		{"app", "", "Your app is <b>old</b>\n\n" + appText},
+		{"rich", "on", "Okay, your rich mode is now <b>on</b>"},
+		{"rich", "", "Your rich mode is <b>on</b>\n\n" + richText},
+	} {
+		if resp := obj.runCommand(1, test.cmd, test.args); resp != test.expected {
+			t.Errorf("Expected %q to equal %q", resp, test.expected)
+		}
+	}
+
+	expected := []string{
+		"set/1/style/detailed",
+		"set/1/style/",
+		"set/1/app/old",
+		"set/1/rich/on",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestNotifyStyle(t *testing.T) {
+	var actual []string
+	obj := testHandler(&actual)
+	obj.settings = testSettings(&actual, map[string]string{
+		"style": "link",
+		"app":   "new",
+	})
+
+	obj.notify(1, notifier.Notification{Title: "Tada68", Permalink: "/r/mechmarket/abc", Reason: "matched selling tada68"})
+
+	expected := []string{`msg/1/<a href="https://www.reddit.com/r/mechmarket/abc">Tada68</a>`}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
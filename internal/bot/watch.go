@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+)
+
+// keywordSplitRex separates the keywords of a bulk command
+var keywordSplitRex = regexp.MustCompile(`[,\n]`)
+
+// <TYPE> <KEYWORD>[, <KEYWORD>...]
+var watchRex = regexp.MustCompile(`(?is)^(\w+)(?:\s+(.+))?$`)
+
+// splitKeywords breaks a list of comma or newline separated keywords apart
+// Blank and repeated keywords are dropped
+func splitKeywords(list string) []string {
+	keywords := []string{}
+	seen := make(map[string]bool)
+	for _, keyword := range keywordSplitRex.Split(list, -1) {
+		keyword = strings.Join(strings.Fields(keyword), " ")
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
+
+// handleBulkSubscribe adds several keywords at once
+// Unlike a single keyword, existing watches are left alone instead of removed
+func (b *Handler) handleBulkSubscribe(userID int64, cmd string, keywords []string) string {
+	resp := []string{fmt.Sprintf("Okay, here's what changed for <b>%s</b> posts:", html.EscapeString(cmd))}
+
+	for _, keyword := range keywords {
+		escapedKeyword := html.EscapeString(keyword)
+		if len(keyword) > maxKeywordLength {
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>: keyword is too long", html.EscapeString(string([]rune(keyword)[:20])+"...")))
+			continue
+		}
+		if b.data[cmd].Exists(userID, keyword) {
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>: already watching", escapedKeyword))
+			continue
+		}
+
+		err := b.data[cmd].Add(userID, keyword)
+		if err != nil {
+			b.logger.Println("Unable to add keyword: ", err)
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>: unable to add", escapedKeyword))
+			continue
+		}
+		resp = append(resp, fmt.Sprintf(" - <b>%s</b>: watching%s", escapedKeyword, b.scheduleExpiry(userID, cmd, keyword)))
+	}
+
+	return strings.Join(resp, "\n")
+}
+
+// parseWatch splits the arguments of /watch and /unwatch into a type and its keywords
+// An empty list of keywords means ALL posts
+func (b *Handler) parseWatch(args string) (string, []string, bool) {
+	fields := watchRex.FindStringSubmatch(strings.TrimSpace(args))
+	if fields == nil {
+		return "", nil, false
+	}
+
+	keywords := splitKeywords(fields[2])
+	if len(keywords) == 0 {
+		keywords = []string{"*"}
+	}
+	return strings.ToLower(fields[1]), keywords, true
+}
+
+// handleWatch adds one or more keywords without ever removing them
+func (b *Handler) handleWatch(userID int64, args string) string {
+	cmd, keywords, ok := b.parseWatch(args)
+	if !ok {
+		return "Tell me what to watch (e.g. /watch selling tada68, tofu)"
+	}
+	if _, ok := b.data[cmd]; !ok {
+		return fmt.Sprintf("I don't know about <b>%s</b> posts", html.EscapeString(cmd))
+	}
+
+	return b.handleBulkSubscribe(userID, cmd, keywords)
+}
+
+// handleUnwatch removes one or more keywords without ever adding them
+func (b *Handler) handleUnwatch(userID int64, args string) string {
+	cmd, keywords, ok := b.parseWatch(args)
+	if !ok {
+		return "Tell me what to stop watching (e.g. /unwatch selling tada68, tofu)"
+	}
+	if _, ok := b.data[cmd]; !ok {
+		return fmt.Sprintf("I don't know about <b>%s</b> posts", html.EscapeString(cmd))
+	}
+
+	resp := []string{fmt.Sprintf("Okay, here's what changed for <b>%s</b> posts:", html.EscapeString(cmd))}
+	for _, keyword := range keywords {
+		escapedKeyword := html.EscapeString(keyword)
+		if !b.data[cmd].Exists(userID, keyword) {
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>: wasn't watching", escapedKeyword))
+			continue
+		}
+
+		err := b.data[cmd].Remove(userID, keyword)
+		if err != nil {
+			b.logger.Println("Unable to remove keyword: ", err)
+			resp = append(resp, fmt.Sprintf(" - <b>%s</b>: unable to remove", escapedKeyword))
+			continue
+		}
+		b.clearJobs(userID, cmd, keyword)
+		resp = append(resp, fmt.Sprintf(" - <b>%s</b>: no longer watching", escapedKeyword))
+	}
+
+	return strings.Join(resp, "\n")
+}
+
+// clearTypes returns the types cleared by /clear, all of them when empty
+func (b *Handler) clearTypes(arg string) ([]string, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" || arg == "all" {
+		return matcher.Types, true
+	}
+	if _, ok := b.data[arg]; !ok {
+		return nil, false
+	}
+	return []string{arg}, true
+}
+
+// handleClear asks the user to confirm before removing their watches
+func (b *Handler) handleClear(userID int64, arg string) string {
+	types, ok := b.clearTypes(arg)
+	if !ok {
+		return fmt.Sprintf("I don't know about <b>%s</b> posts", html.EscapeString(arg))
+	}
+
+	total := 0
+	for _, t := range types {
+		total += len(b.data[t].Get(userID))
+	}
+	if total == 0 {
+		return "There are no items on your watch list"
+	}
+
+	name := "all"
+	if len(types) == 1 {
+		name = types[0]
+	}
+	question := fmt.Sprintf("Are you sure you want to stop watching <b>%d</b> items (%s)?", total, html.EscapeString(name))
+	err := b.chatFor(userID).SendButtons(userID, question, []chatter.Button{
+		{Text: "Yes, clear them", Data: "clear " + name},
+		{Text: "No, keep them", Data: "cancel"},
+	})
+	if err != nil {
+		b.logger.Println("Unable to send buttons: ", err)
+		return "Sorry, I wasn't able to ask you to confirm that"
+	}
+
+	return ""
+}
+
+// confirmClear removes every watch of the given types after the user confirms
+func (b *Handler) confirmClear(userID int64, arg string) string {
+	types, ok := b.clearTypes(arg)
+	if !ok {
+		return fmt.Sprintf("I don't know about <b>%s</b> posts", html.EscapeString(arg))
+	}
+
+	total := 0
+	for _, t := range types {
+		for keyword := range b.data[t].Get(userID) {
+			err := b.data[t].Remove(userID, keyword)
+			if err != nil {
+				b.logger.Println("Unable to remove keyword: ", err)
+				continue
+			}
+			b.clearJobs(userID, t, keyword)
+			total++
+		}
+	}
+
+	return fmt.Sprintf("Okay, I'm no longer watching for <b>%d</b> items", total)
+}
@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+	"github.com/stjohnjohnson/reddit-watcher/internal/data"
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+	"github.com/stjohnjohnson/reddit-watcher/mocks"
+)
+
+func TestSplitKeywords(t *testing.T) {
+	actual := splitKeywords("tada68, tofu\n\n kbd67  lite ,TADA68,")
+	expected := []string{"tada68", "tofu", "kbd67 lite"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageBulkSubscribe(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "tofu"
+		},
+		MockAdd: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("add/%d/%s", i, s))
+			return nil
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data:     data,
+		schedule: &mocks.Scheduler{},
+	}
+
+	err := obj.incomingMessage(1, "/selling tada68, tofu\nkbd67 once")
+
+	if !reflect.DeepEqual(err, nil) {
+		t.Errorf("Expected nil, got %q", err)
+	}
+	expected := []string{
+		"add/1/tada68",
+		"add/1/kbd67 once",
+		"msg/1/Okay, here's what changed for <b>selling</b> posts:\n - <b>tada68</b>: watching\n - <b>tofu</b>: already watching\n - <b>kbd67 once</b>: watching until the first match",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageUnwatch(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Selling] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "tofu"
+		},
+		MockAdd: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("add/%d/%s", i, s))
+			return nil
+		},
+		MockRemove: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("rm/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data:     data,
+		schedule: &mocks.Scheduler{},
+	}
+
+	for _, message := range []string{"/unwatch Selling tada68, tofu", "/unwatch bartering tofu", "/unwatch"} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"rm/1/tofu",
+		"msg/1/Okay, here's what changed for <b>selling</b> posts:\n - <b>tada68</b>: wasn't watching\n - <b>tofu</b>: no longer watching",
+		"msg/1/I don't know about <b>bartering</b> posts",
+		"msg/1/Tell me what to stop watching (e.g. /unwatch selling tada68, tofu)",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageWatch(t *testing.T) {
+	var actual []string
+	data := make(map[string]data.Interface)
+	data[matcher.Artisan] = &mocks.Data{
+		MockExists: func(i int64, s string) bool {
+			return s == "*"
+		},
+		MockAdd: func(i int64, s string) error {
+			actual = append(actual, fmt.Sprintf("add/%d/%s", i, s))
+			return nil
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+		},
+		data:     data,
+		schedule: &mocks.Scheduler{},
+	}
+
+	for _, message := range []string{"/watch artisan fugu", "/watch artisan", "/watch"} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"add/1/fugu",
+		"msg/1/Okay, here's what changed for <b>artisan</b> posts:\n - <b>fugu</b>: watching",
+		"msg/1/Okay, here's what changed for <b>artisan</b> posts:\n - <b>*</b>: already watching",
+		"msg/1/Tell me what to watch (e.g. /watch selling tada68, tofu)",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestMessageClear(t *testing.T) {
+	var actual []string
+	d := make(map[string]data.Interface)
+	for _, t := range matcher.Types {
+		d[t] = &mocks.Data{
+			MockGet: func(int64) data.Keywords {
+				return make(data.Keywords)
+			},
+		}
+	}
+	d[matcher.Selling] = &mocks.Data{
+		MockGet: func(int64) data.Keywords {
+			return data.Keywords{"tada68": 2, "tofu": 0}
+		},
+	}
+	obj := &Handler{
+		logger: log.New(ioutil.Discard, "", 0),
+		chat: &mocks.Chatter{
+			MockSendMessage: func(i int64, s string) error {
+				actual = append(actual, fmt.Sprintf("msg/%d/%s", i, s))
+				return nil
+			},
+			MockSendButtons: func(i int64, s string, b []chatter.Button) error {
+				actual = append(actual, fmt.Sprintf("buttons/%d/%s/%v", i, s, b))
+				return nil
+			},
+		},
+		data: d,
+	}
+
+	for _, message := range []string{"/clear", "/clear buying", "/clear Selling", "/clear bartering"} {
+		err := obj.incomingMessage(1, message)
+		if !reflect.DeepEqual(err, nil) {
+			t.Errorf("Expected nil, got %q", err)
+		}
+	}
+
+	expected := []string{
+		"buttons/1/Are you sure you want to stop watching <b>2</b> items (all)?/[{Yes, clear them clear all} {No, keep them cancel}]",
+		"msg/1/There are no items on your watch list",
+		"buttons/1/Are you sure you want to stop watching <b>2</b> items (selling)?/[{Yes, clear them clear selling} {No, keep them cancel}]",
+		"msg/1/I don't know about <b>bartering</b> posts",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
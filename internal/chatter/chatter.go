@@ -2,12 +2,18 @@ package chatter
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 
 	"gopkg.in/telegram-bot-api.v4"
 )
 
+// maxFileSize is the largest file that will be downloaded from a user
+const maxFileSize = 1 << 20
+
 // Handler is a telegram bot
 type Handler struct {
 	bot    *tgbotapi.BotAPI
@@ -17,7 +23,23 @@ type Handler struct {
 // Interface is the stats public functions
 type Interface interface {
 	Start() (Channel, error)
+	Username() string
+	IsChatAdmin(int64, int) (bool, error)
 	SendMessage(int64, string) error
+	SendButtons(int64, string, []Button) error
+	SendKeyboard(int64, string, [][]Button) error
+	EditMessage(int64, int, string) error
+	EditKeyboard(int64, int, string, [][]Button) error
+	AnswerCallback(string, string) error
+	SendDocument(int64, string, []byte, string) error
+	SendPhoto(int64, string, string) error
+	GetFile(string) ([]byte, error)
+}
+
+// Button is an inline keyboard button that sends its data back when pressed
+type Button struct {
+	Text string
+	Data string
 }
 
 // Channel is a message channel
@@ -36,11 +58,76 @@ func (r *Handler) Start() (Channel, error) {
 	return Channel(c), nil
 }
 
+// Username is the name of the bot, used to address commands in group chats
+func (r *Handler) Username() string {
+	return r.bot.Self.UserName
+}
+
+// IsChatAdmin checks if a user created or administers a chat
+func (r *Handler) IsChatAdmin(chatID int64, userID int) (bool, error) {
+	member, err := r.bot.GetChatMember(tgbotapi.ChatConfigWithUser{
+		ChatID: chatID,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Unable to get chat member: %v", err)
+	}
+	return member.IsCreator() || member.IsAdministrator(), nil
+}
+
 // SendMessage will send a message to a given user
+// Messages too long for Telegram are sent in parts
 func (r *Handler) SendMessage(chatID int64, message string) error {
+	for _, part := range split(message, maxMessageLength) {
+		err := r.send(chatID, part, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendButtons will send a message with a row of buttons to a given user
+func (r *Handler) SendButtons(chatID int64, message string, buttons []Button) error {
+	return r.SendKeyboard(chatID, message, [][]Button{buttons})
+}
+
+// SendKeyboard will send a message with rows of buttons to a given user
+// The buttons go on the last part of messages too long for Telegram
+func (r *Handler) SendKeyboard(chatID int64, message string, rows [][]Button) error {
+	parts := split(message, maxMessageLength)
+	for i, part := range parts {
+		var markup interface{}
+		if i == len(parts)-1 {
+			markup = keyboard(rows)
+		}
+		err := r.send(chatID, part, markup)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyboard turns rows of buttons into an inline keyboard
+func keyboard(rows [][]Button) tgbotapi.InlineKeyboardMarkup {
+	keys := [][]tgbotapi.InlineKeyboardButton{}
+	for _, buttons := range rows {
+		row := []tgbotapi.InlineKeyboardButton{}
+		for _, button := range buttons {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(button.Text, button.Data))
+		}
+		keys = append(keys, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(keys...)
+}
+
+// send sends one part of a message
+func (r *Handler) send(chatID int64, message string, markup interface{}) error {
 	msg := tgbotapi.NewMessage(chatID, message)
 	msg.ParseMode = tgbotapi.ModeHTML
 	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = markup
 	_, err := r.bot.Send(msg)
 
 	if err != nil {
@@ -49,6 +136,108 @@ func (r *Handler) SendMessage(chatID int64, message string) error {
 	return nil
 }
 
+// EditMessage will replace the text of a sent message, removing any buttons
+// Only the first part of a message too long for Telegram replaces it, the
+// rest are sent after it
+func (r *Handler) EditMessage(chatID int64, messageID int, message string) error {
+	parts := split(message, maxMessageLength)
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, parts[0])
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.DisableWebPagePreview = true
+	_, err := r.bot.Send(edit)
+
+	if err != nil {
+		return fmt.Errorf("Unable to edit: %v", err)
+	}
+
+	for _, part := range parts[1:] {
+		err = r.send(chatID, part, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EditKeyboard will replace the text and buttons of a sent message
+func (r *Handler) EditKeyboard(chatID int64, messageID int, message string, rows [][]Button) error {
+	// The buttons belong to this message, so anything past the length limit is cut
+	markup := keyboard(rows)
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, split(message, maxMessageLength)[0])
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.DisableWebPagePreview = true
+	edit.ReplyMarkup = &markup
+	_, err := r.bot.Send(edit)
+
+	if err != nil {
+		return fmt.Errorf("Unable to edit: %v", err)
+	}
+	return nil
+}
+
+// AnswerCallback will acknowledge a button press, optionally showing a notice
+func (r *Handler) AnswerCallback(callbackID, notice string) error {
+	_, err := r.bot.AnswerCallbackQuery(tgbotapi.NewCallback(callbackID, notice))
+
+	if err != nil {
+		return fmt.Errorf("Unable to answer: %v", err)
+	}
+	return nil
+}
+
+// SendDocument will send a file with a caption to a given user
+func (r *Handler) SendDocument(chatID int64, name string, contents []byte, caption string) error {
+	doc := tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{
+		Name:  name,
+		Bytes: contents,
+	})
+	doc.Caption = caption
+	_, err := r.bot.Send(doc)
+
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	return nil
+}
+
+// SendPhoto will send a photo from a URL with an HTML caption to a given user
+// Telegram downloads the photo itself, so it fails for anything that isn't an image
+func (r *Handler) SendPhoto(chatID int64, photoURL, caption string) error {
+	photo := tgbotapi.NewPhotoShare(chatID, photoURL)
+	photo.Caption = caption
+	photo.ParseMode = tgbotapi.ModeHTML
+	_, err := r.bot.Send(photo)
+
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	return nil
+}
+
+// GetFile downloads a file that a user sent
+func (r *Handler) GetFile(fileID string) ([]byte, error) {
+	url, err := r.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to find file: %v", err)
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to download file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to download file: %s", resp.Status)
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read file: %v", err)
+	}
+	return contents, nil
+}
+
 // New creates a new Telegram bot
 func New(version, token string) (*Handler, error) {
 	logger := log.New(os.Stderr, "[CHAT] ", log.LstdFlags)
@@ -0,0 +1,133 @@
+package chatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxMessageLength is the longest message Telegram allows, in UTF-16 code units
+const maxMessageLength = 4096
+
+// tokenRex matches the pieces a message can be split between: tags,
+// entities, line breaks, spaces and words of up to 100 characters
+var tokenRex = regexp.MustCompile(`<[^>]*>|&#?\w+;|\n|[ \t]+|[^<&\s]{1,100}|.`)
+
+// tagRex matches an opening or closing tag, capturing the slash and name
+var tagRex = regexp.MustCompile(`^<(/?)(\w+)`)
+
+// length counts text the way Telegram does
+func length(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// apply returns the tags open after a token, given those open before it
+func apply(open []string, token string) []string {
+	m := tagRex.FindStringSubmatch(token)
+	switch {
+	case m == nil:
+		return open
+	case m[1] == "":
+		return append(append([]string{}, open...), token)
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		if tagRex.FindStringSubmatch(open[i])[2] == m[2] {
+			return open[:i]
+		}
+	}
+	return open
+}
+
+// openTags returns the tags still open after the tokens, starting with those already open
+func openTags(open []string, tokens []string) []string {
+	for _, token := range tokens {
+		open = apply(open, token)
+	}
+	return open
+}
+
+// closeTags returns the closing tags for open tags, innermost first
+func closeTags(open []string) string {
+	closing := ""
+	for i := len(open) - 1; i >= 0; i-- {
+		closing += "</" + tagRex.FindStringSubmatch(open[i])[2] + ">"
+	}
+	return closing
+}
+
+// split breaks an HTML message into messages of at most limit, preferring
+// line breaks and then spaces, and closing and reopening tags around each break
+func split(message string, limit int) []string {
+	if length(message) <= limit {
+		return []string{message}
+	}
+
+	messages := []string{}
+	tokens := tokenRex.FindAllString(message, -1)
+	open := []string{}
+	for len(tokens) > 0 {
+		prefix := strings.Join(open, "")
+
+		// Take tokens until the next one won't fit with the tags it leaves open
+		end, size, stack := 0, length(prefix), open
+		for end < len(tokens) {
+			next := size + length(tokens[end])
+			stack = apply(stack, tokens[end])
+			if next+length(closeTags(stack)) > limit {
+				break
+			}
+			size = next
+			end++
+		}
+
+		// Words too long for a message of their own are cut wherever they fill it
+		if end == 0 && !strings.HasPrefix(tokens[0], "<") {
+			runes := []rune(tokens[0])
+			n := 0
+			for n < len(runes) && size+length(string(runes[:n+1]))+length(closeTags(open)) <= limit {
+				n++
+			}
+			if n > 0 {
+				tokens = append([]string{string(runes[:n]), string(runes[n:])}, tokens[1:]...)
+				end = 1
+			}
+		}
+
+		// Break at the last line, or the last space, unless everything fits
+		cut := end
+		if end < len(tokens) {
+			for _, sep := range []string{"\n", " "} {
+				found := false
+				for i := end; i > 0; i-- {
+					if strings.HasPrefix(tokens[i], sep) {
+						cut, found = i, true
+						break
+					}
+				}
+				if found {
+					break
+				}
+			}
+		}
+		if cut == 0 {
+			cut = 1
+		}
+
+		chunk := tokens[:cut]
+		stillOpen := openTags(open, chunk)
+		text := prefix + strings.Join(chunk, "") + closeTags(stillOpen)
+		if strings.TrimSpace(text) != "" {
+			messages = append(messages, text)
+		}
+
+		// Drop the break itself
+		tokens = tokens[cut:]
+		if len(tokens) > 0 && strings.TrimSpace(tokens[0]) == "" {
+			tokens = tokens[1:]
+		}
+		open = stillOpen
+	}
+
+	return messages
+}
@@ -0,0 +1,54 @@
+package chatter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	for _, test := range []struct {
+		message  string
+		limit    int
+		expected []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"one\ntwo\nthree", 8, []string{"one\ntwo", "three"}},
+		{"one two three four", 9, []string{"one two", "three", "four"}},
+		{"<b>one\ntwo</b>\nthree", 12, []string{"<b>one</b>", "<b>two</b>", "three"}},
+		{`<a href="https://example.com">one two</a> three`, 40, []string{`<a href="https://example.com">one</a>`, `<a href="https://example.com">two</a>`, "three"}},
+		{"a &amp; b &amp; c", 9, []string{"a &amp; b", "&amp; c"}},
+		{"😀😀😀", 4, []string{"😀😀", "😀"}},
+		{strings.Repeat("x", 150), 120, []string{strings.Repeat("x", 100), strings.Repeat("x", 50)}},
+	} {
+		actual := split(test.message, test.limit)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Expected %q to equal %q", actual, test.expected)
+		}
+		for _, message := range actual {
+			if length(message) > test.limit {
+				t.Errorf("Expected %q to fit in %d", message, test.limit)
+			}
+		}
+	}
+}
+
+func TestSplitWatchlist(t *testing.T) {
+	lines := []string{"Your watch list:"}
+	for i := 0; i < 500; i++ {
+		lines = append(lines, " - <b>selling</b>: <i>tada68 with a long keyword</i> (12 hits)")
+	}
+
+	messages := split(strings.Join(lines, "\n"), maxMessageLength)
+	if len(messages) != 8 {
+		t.Errorf("Expected 8 messages, got %d", len(messages))
+	}
+	for _, message := range messages {
+		if length(message) > maxMessageLength || strings.Count(message, "<b>") != strings.Count(message, "</b>") {
+			t.Errorf("Expected a whole message that fits, got %d characters", length(message))
+		}
+	}
+	if strings.Join(messages, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("Expected the messages to add up to the original")
+	}
+}
@@ -2,7 +2,9 @@ package data
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/matryer/persist"
 )
@@ -10,12 +12,19 @@ import (
 // Keywords keeps track of the criteria and number of hits
 type Keywords map[string]int
 
+// Details keeps track of when a keyword was added and last matched
+type Details struct {
+	Created time.Time
+	LastHit time.Time
+}
+
 // Handler represents all information stored by the application
 type Handler struct {
-	keyMap   map[string][]int64
-	userMap  map[int64]Keywords
-	keywords []string
-	path     string
+	keyMap     map[string][]int64
+	userMap    map[int64]Keywords
+	detailsMap map[int64]map[string]Details
+	keywords   []string
+	path       string
 }
 
 // Interface is the stats public functions
@@ -23,11 +32,13 @@ type Interface interface {
 	Get(int64) Keywords
 	GetByKeyword(string) []int64
 	GetKeywords() []string
+	GetUsers() []int64
 	Sync()
 	Add(int64, string) error
 	Exists(int64, string) bool
 	Remove(int64, string) error
 	Increment(int64, string) error
+	Details(int64, string) Details
 }
 
 // Get returns the map of Keywords per user ID
@@ -55,6 +66,19 @@ func (ud *Handler) GetKeywords() []string {
 	return ud.keywords
 }
 
+// GetUsers returns the sorted list of user IDs watching at least one keyword
+func (ud *Handler) GetUsers() []int64 {
+	ids := []int64{}
+	for id, keys := range ud.userMap {
+		if len(keys) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
 // Sync updates the temporary variables keyMap and keywords
 func (ud *Handler) Sync() {
 	keyMap := make(map[string][]int64)
@@ -74,9 +98,25 @@ func (ud *Handler) Sync() {
 	ud.keywords = keywords
 }
 
+// Details returns when a keyword was added and last matched for a given user ID
+func (ud *Handler) Details(id int64, keyword string) Details {
+	return ud.detailsMap[id][strings.ToLower(keyword)]
+}
+
+// setDetails updates the details of a keyword for a given user ID
+func (ud *Handler) setDetails(id int64, keyword string, details Details) {
+	_, ok := ud.detailsMap[id]
+	if !ok {
+		ud.detailsMap[id] = make(map[string]Details)
+	}
+
+	ud.detailsMap[id][strings.ToLower(keyword)] = details
+}
+
 // Add watches a keyword for a given user ID
 func (ud *Handler) Add(id int64, keyword string) error {
 	ud.Get(id)[strings.ToLower(keyword)] = 0
+	ud.setDetails(id, keyword, Details{Created: time.Now()})
 	ud.Sync()
 
 	return ud.save()
@@ -91,6 +131,7 @@ func (ud *Handler) Exists(id int64, keyword string) bool {
 // Remove no longer watches a keyword for a given user ID
 func (ud *Handler) Remove(id int64, keyword string) error {
 	delete(ud.Get(id), strings.ToLower(keyword))
+	delete(ud.detailsMap[id], strings.ToLower(keyword))
 	ud.Sync()
 
 	return ud.save()
@@ -100,6 +141,10 @@ func (ud *Handler) Remove(id int64, keyword string) error {
 func (ud *Handler) Increment(id int64, keyword string) error {
 	ud.Get(id)[strings.ToLower(keyword)]++
 
+	details := ud.Details(id, keyword)
+	details.LastHit = time.Now()
+	ud.setDetails(id, keyword, details)
+
 	return ud.save()
 }
 
@@ -110,6 +155,11 @@ func (ud *Handler) save() error {
 		return fmt.Errorf("save data failed: %v", err)
 	}
 
+	err = persist.Save(fmt.Sprintf("%s.details.json", ud.path), ud.detailsMap)
+	if err != nil {
+		return fmt.Errorf("save details failed: %v", err)
+	}
+
 	return nil
 }
 
@@ -122,9 +172,16 @@ func Load(path string) (*Handler, error) {
 		userMap = make(map[int64]Keywords)
 	}
 
+	// Details are optional, older config directories won't have them
+	var detailsMap map[int64]map[string]Details
+	if persist.Load(fmt.Sprintf("%s.details.json", path), &detailsMap) != nil {
+		detailsMap = make(map[int64]map[string]Details)
+	}
+
 	appData := &Handler{
-		userMap: userMap,
-		path:    path,
+		userMap:    userMap,
+		detailsMap: detailsMap,
+		path:       path,
 	}
 	appData.Sync()
 
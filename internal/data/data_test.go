@@ -2,6 +2,7 @@ package data
 
 import (
 	"testing"
+	"time"
 )
 
 func TestSave(t *testing.T) {
@@ -68,3 +69,48 @@ func TestKeywords(t *testing.T) {
 		t.Errorf("Expected ids to be 2, got %+v", ids)
 	}
 }
+
+func TestDetails(t *testing.T) {
+	obj, _ := Load("/tmp/details")
+	start := time.Now()
+
+	obj.Add(1, "Foo")
+	details := obj.Details(1, "foo")
+	if details.Created.Before(start) || !details.LastHit.IsZero() {
+		t.Errorf("Expected only created to be set, got %+v", details)
+	}
+
+	obj.Increment(1, "foo")
+	details = obj.Details(1, "FOO")
+	if details.LastHit.Before(details.Created) {
+		t.Errorf("Expected last hit to be set, got %+v", details)
+	}
+
+	obj, err := Load("/tmp/details")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if !obj.Details(1, "foo").LastHit.Equal(details.LastHit) {
+		t.Errorf("Expected details to be saved, got %+v", obj.Details(1, "foo"))
+	}
+
+	obj.Remove(1, "foo")
+	details = obj.Details(1, "foo")
+	if !details.Created.IsZero() {
+		t.Errorf("Expected details to be removed, got %+v", details)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	obj, _ := Load("/tmp/foo/users")
+
+	obj.Add(3, "foo")
+	obj.Add(1, "bar")
+	obj.Add(2, "baz")
+	obj.Remove(2, "baz")
+
+	ids := obj.GetUsers()
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("Expected ids to be [1 3], got %+v", ids)
+	}
+}
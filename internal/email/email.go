@@ -0,0 +1,201 @@
+package email
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// unsubscribePath is where the one-click unsubscribe links point to
+const unsubscribePath = "/email/unsubscribe"
+
+var confirmTemplate = `<!DOCTYPE html>
+<html><body>
+<form method="post" action="%s">
+<p>Stop sending matches to <b>%s</b>?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+</body></html>`
+
+var doneTemplate = `<!DOCTYPE html>
+<html><body>
+<p>Okay, matches are no longer sent to <b>%s</b>.</p>
+</body></html>`
+
+// Channel is a channel of addresses that unsubscribed
+type Channel chan string
+
+// Handler sends email over SMTP and accepts one-click unsubscribes over HTTP
+type Handler struct {
+	addr    string
+	auth    smtp.Auth
+	from    string
+	secret  string
+	baseURL string
+	send    func(string, smtp.Auth, string, []string, []byte) error
+	channel Channel
+	now     func() time.Time
+	logger  *log.Logger
+}
+
+// Interface is the email public functions
+type Interface interface {
+	Start() (Channel, error)
+	Send(string, string, string, string) error
+	UnsubscribeURL(string) string
+}
+
+// Start returns the channel of unsubscribed addresses
+// Requests are served by the bot's web server through ServeHTTP
+func (e *Handler) Start() (Channel, error) {
+	return e.channel, nil
+}
+
+// token signs an address so only the links sent to it can unsubscribe it
+func (e *Handler) token(address string) string {
+	mac := hmac.New(sha256.New, []byte(e.secret))
+	mac.Write([]byte(strings.ToLower(address)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeURL is the link that stops matches being sent to an address
+func (e *Handler) UnsubscribeURL(address string) string {
+	query := url.Values{"address": {address}, "token": {e.token(address)}}
+	return fmt.Sprintf("%s%s?%s", e.baseURL, unsubscribePath, query.Encode())
+}
+
+// Send emails a plain text and HTML version of a message to an address
+func (e *Handler) Send(to, subject, text, htmlBody string) error {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		contents    string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return fmt.Errorf("Unable to write message: %v", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		qp.Write([]byte(part.contents))
+		qp.Close()
+	}
+	parts.Close()
+
+	var msg bytes.Buffer
+	for _, header := range [][2]string{
+		{"From", e.from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", e.now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+		{"List-Unsubscribe", fmt.Sprintf("<%s>", e.UnsubscribeURL(to))},
+		{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+	} {
+		fmt.Fprintf(&msg, "%s: %s\r\n", header[0], header[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	sender, err := mail.ParseAddress(e.from)
+	if err != nil {
+		return fmt.Errorf("Unable to send: invalid from address: %v", err)
+	}
+
+	err = e.send(e.addr, e.auth, sender.Address, []string{to}, msg.Bytes())
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	return nil
+}
+
+// ServeHTTP accepts unsubscribes on /email/unsubscribe
+// Mail clients POST to it directly, people following the link confirm first
+// so that link scanners can't unsubscribe anyone
+func (e *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != unsubscribePath {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	address := query.Get("address")
+	if address == "" || !hmac.Equal([]byte(e.token(address)), []byte(query.Get("token"))) {
+		e.logger.Printf("Rejected unsubscribe for %q", address)
+		http.Error(w, "invalid unsubscribe link", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	switch r.Method {
+	case http.MethodGet:
+		fmt.Fprintf(w, confirmTemplate, html.EscapeString(r.URL.RequestURI()), html.EscapeString(address))
+
+	case http.MethodPost:
+		select {
+		case e.channel <- address:
+			fmt.Fprintf(w, doneTemplate, html.EscapeString(address))
+		case <-time.After(2 * time.Second):
+			http.Error(w, "too busy", http.StatusServiceUnavailable)
+		}
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// New creates an email sender given an SMTP server (host:port) and sender address
+// Unsubscribe links are signed with the secret and point to the base URL
+func New(addr, username, password, from, secret, baseURL string) (*Handler, error) {
+	if addr == "" || from == "" || secret == "" || baseURL == "" {
+		return nil, fmt.Errorf("Unable to setup: server, from address, secret and public URL are required")
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("Unable to setup: invalid from address: %v", err)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to setup: invalid server address: %v", err)
+		}
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &Handler{
+		addr:    addr,
+		auth:    auth,
+		from:    from,
+		secret:  secret,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		send:    smtp.SendMail,
+		channel: make(Channel, 100),
+		now:     time.Now,
+		logger:  log.New(os.Stderr, "[EMAIL] ", log.LstdFlags),
+	}, nil
+}
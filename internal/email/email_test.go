@@ -0,0 +1,234 @@
+package email
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer is a local SMTP stand-in that keeps every message it's sent
+type smtpServer struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+
+	s := &smtpServer{listener: listener, messages: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// serve speaks just enough SMTP for net/smtp to deliver a message
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 localhost ready\r\n")
+
+	var envelope []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			fmt.Fprint(conn, "250 localhost\r\n")
+		case "MAIL", "RCPT":
+			envelope = append(envelope, line)
+			fmt.Fprint(conn, "250 OK\r\n")
+		case "DATA":
+			fmt.Fprint(conn, "354 go ahead\r\n")
+			var data []string
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data = append(data, line)
+			}
+			s.messages <- strings.Join(envelope, "\n") + "\n\n" + strings.Join(data, "")
+			fmt.Fprint(conn, "250 OK\r\n")
+		case "QUIT":
+			fmt.Fprint(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 OK\r\n")
+		}
+	}
+}
+
+func testHandler(addr string) *Handler {
+	obj, _ := New(addr, "", "", "Mech Market <bot@example.com>", "secret", "https://bot.example.com/")
+	obj.now = func() time.Time { return time.Unix(1500000000, 0).UTC() }
+	obj.logger = log.New(ioutil.Discard, "", 0)
+	return obj
+}
+
+func TestSend(t *testing.T) {
+	server := newSMTPServer(t)
+	defer server.listener.Close()
+	obj := testHandler(server.listener.Addr().String())
+
+	err := obj.Send("you@example.com", "Tada68 ✓", "Tada68 is for sale", "<b>Tada68</b> is for sale")
+	if err != nil {
+		t.Fatalf("Expected no error, got %+v", err)
+	}
+
+	raw := <-server.messages
+	parts := strings.SplitN(raw, "\n\n", 2)
+	expected := "MAIL FROM:<bot@example.com>\nRCPT TO:<you@example.com>"
+	if parts[0] != expected {
+		t.Errorf("Expected %q to equal %q", parts[0], expected)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(parts[1]))
+	if err != nil {
+		t.Fatalf("Expected a valid message, got %+v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	headers := []string{
+		msg.Header.Get("From"),
+		msg.Header.Get("To"),
+		subject,
+		msg.Header.Get("Date"),
+		msg.Header.Get("List-Unsubscribe"),
+		msg.Header.Get("List-Unsubscribe-Post"),
+	}
+	expectedHeaders := []string{
+		"Mech Market <bot@example.com>",
+		"you@example.com",
+		"Tada68 ✓",
+		"Fri, 14 Jul 2017 02:40:00 +0000",
+		"<" + obj.UnsubscribeURL("you@example.com") + ">",
+		"List-Unsubscribe=One-Click",
+	}
+	if !reflect.DeepEqual(headers, expectedHeaders) {
+		t.Errorf("Expected %q to equal %q", headers, expectedHeaders)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Expected a multipart message, got %s", mediaType)
+	}
+	var bodies []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		contents, _ := ioutil.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+"|"+string(contents))
+	}
+	expectedBodies := []string{
+		"text/plain; charset=utf-8|Tada68 is for sale",
+		"text/html; charset=utf-8|<b>Tada68</b> is for sale",
+	}
+	if !reflect.DeepEqual(bodies, expectedBodies) {
+		t.Errorf("Expected %q to equal %q", bodies, expectedBodies)
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	server := newSMTPServer(t)
+	addr := server.listener.Addr().String()
+	server.listener.Close()
+
+	err := testHandler(addr).Send("you@example.com", "Tada68", "", "")
+	if err == nil || !strings.HasPrefix(err.Error(), "Unable to send:") {
+		t.Errorf("Expected an error, got %+v", err)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	obj := testHandler("localhost:25")
+	link, _ := url.Parse(obj.UnsubscribeURL("you@example.com"))
+	if link.Host != "bot.example.com" || link.Path != "/email/unsubscribe" {
+		t.Errorf("Expected a link to the public URL, got %s", link)
+	}
+
+	w := httptest.NewRecorder()
+	obj.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link.RequestURI(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<form method="post"`) {
+		t.Errorf("Expected a confirmation page, got %d %s", w.Code, w.Body.String())
+	}
+	if len(obj.channel) != 0 {
+		t.Errorf("Expected following the link not to unsubscribe")
+	}
+
+	w = httptest.NewRecorder()
+	obj.ServeHTTP(w, httptest.NewRequest(http.MethodPost, link.RequestURI(), strings.NewReader("List-Unsubscribe=One-Click")))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+	if actual := <-obj.channel; actual != "you@example.com" {
+		t.Errorf("Expected %q to equal %q", actual, "you@example.com")
+	}
+}
+
+func TestUnsubscribeRejected(t *testing.T) {
+	obj := testHandler("localhost:25")
+	forged := strings.Replace(obj.UnsubscribeURL("you@example.com"), "you%40", "them%40", 1)
+	link, _ := url.Parse(forged)
+
+	for _, test := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{http.MethodPost, link.RequestURI(), http.StatusForbidden},
+		{http.MethodPost, "/email/unsubscribe", http.StatusForbidden},
+		{http.MethodPost, "/email/other", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		obj.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+		if w.Code != test.code {
+			t.Errorf("Expected %d for %s, got %d", test.code, test.target, w.Code)
+		}
+	}
+	if len(obj.channel) != 0 {
+		t.Errorf("Expected no unsubscribes, got %d", len(obj.channel))
+	}
+}
+
+func TestNewMissingConfig(t *testing.T) {
+	_, err := New("localhost:25", "", "", "not an address", "secret", "https://bot.example.com")
+	if err == nil {
+		t.Errorf("Expected an error with an invalid from address")
+	}
+	_, err = New("localhost:25", "", "", "bot@example.com", "", "https://bot.example.com")
+	if err == nil {
+		t.Errorf("Expected an error without a secret")
+	}
+}
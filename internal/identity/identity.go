@@ -0,0 +1,235 @@
+package identity
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matryer/persist"
+)
+
+// Offset is the first user ID given to accounts from transports without numeric IDs
+// Telegram chat IDs stay below it, so existing watch lists keep working
+const Offset int64 = 1 << 50
+
+// CodeTimeout is how long a link code can be used for
+const CodeTimeout = time.Hour
+
+// MaxFailures is how many wrong codes a user can send before linking is locked for them
+const MaxFailures = 5
+
+// ErrLocked is returned by Redeem when too many wrong codes have been sent
+// Linking unlocks once CodeTimeout has passed since the first wrong code
+var ErrLocked = errors.New("too many wrong codes")
+
+// directory is the representation saved to disk
+type directory struct {
+	Next int64
+	// IDs are the user ID of each account, linked accounts share one
+	IDs map[string]int64
+	// Homes are the account each user ID was first given to
+	Homes map[int64]string
+}
+
+// linkCode is a code waiting to be sent from another account
+type linkCode struct {
+	id      int64
+	expires time.Time
+}
+
+// failures counts the wrong codes sent since the first one
+type failures struct {
+	count int
+	since time.Time
+}
+
+// locked checks if MaxFailures wrong codes were sent within CodeTimeout
+func (f failures) locked(now time.Time) bool {
+	return f.count >= MaxFailures && now.Before(f.since.Add(CodeTimeout))
+}
+
+// add counts another wrong code, starting over once CodeTimeout has passed
+func (f failures) add(now time.Time) failures {
+	if !now.Before(f.since.Add(CodeTimeout)) {
+		return failures{count: 1, since: now}
+	}
+	f.count++
+	return f
+}
+
+// Handler maps accounts on every transport to watcher user IDs
+type Handler struct {
+	directory directory
+	codes     map[string]linkCode
+	failed    map[int64]failures
+	path      string
+	now       func() time.Time
+	lock      sync.Mutex
+}
+
+// Interface is the identity public functions
+type Interface interface {
+	Resolve(string, string) (int64, error)
+	Claim(string, string, int64) (int64, error)
+	Lookup(int64) (string, string, bool)
+	NewCode(int64) (string, error)
+	Redeem(string, int64) (int64, bool, error)
+}
+
+// key joins a transport and account into the name saved to disk
+func key(transport, account string) string {
+	return fmt.Sprintf("%s:%s", transport, account)
+}
+
+// Resolve returns the user ID for an account, giving it a new one the first time
+func (i *Handler) Resolve(transport, account string) (int64, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if id, ok := i.directory.IDs[key(transport, account)]; ok {
+		return id, nil
+	}
+
+	id := i.directory.Next
+	i.directory.Next++
+	i.add(key(transport, account), id)
+
+	return id, i.save()
+}
+
+// Claim returns the user ID for an account, giving it the one it already has
+// the first time (e.g. a Telegram chat keeps its chat ID)
+func (i *Handler) Claim(transport, account string, id int64) (int64, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if existing, ok := i.directory.IDs[key(transport, account)]; ok {
+		return existing, nil
+	}
+
+	i.add(key(transport, account), id)
+	return id, i.save()
+}
+
+// add gives an account a user ID, making it the home of IDs without one
+func (i *Handler) add(name string, id int64) {
+	i.directory.IDs[name] = id
+	if _, ok := i.directory.Homes[id]; !ok {
+		i.directory.Homes[id] = name
+	}
+}
+
+// Lookup returns the transport and account a user ID was first given to
+func (i *Handler) Lookup(id int64) (string, string, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	name, ok := i.directory.Homes[id]
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.SplitN(name, ":", 2)
+	return parts[0], parts[1], true
+}
+
+// NewCode returns a random code that links another account to a user ID
+// Codes aren't saved, so they're forgotten on restart
+func (i *Handler) NewCode(id int64) (string, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for code, pending := range i.codes {
+		if pending.id == id || i.now().After(pending.expires) {
+			delete(i.codes, code)
+		}
+	}
+
+	for {
+		n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+		if err != nil {
+			return "", fmt.Errorf("create code failed: %v", err)
+		}
+		code := fmt.Sprintf("%08d", n.Int64())
+		if _, ok := i.codes[code]; ok {
+			continue
+		}
+
+		i.codes[code] = linkCode{id: id, expires: i.now().Add(CodeTimeout)}
+		return code, nil
+	}
+}
+
+// Redeem moves every account of a user ID to the user ID a code was made for,
+// returning that ID and whether the code was valid
+// Codes can only be used once, and wrong codes are limited per user so they can't be guessed
+func (i *Handler) Redeem(code string, from int64) (int64, bool, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	now := i.now()
+	if i.failed[from].locked(now) {
+		return 0, false, ErrLocked
+	}
+
+	code = strings.TrimSpace(code)
+	pending, ok := i.codes[code]
+	if !ok || now.After(pending.expires) {
+		i.failed[from] = i.failed[from].add(now)
+		return 0, false, nil
+	}
+	delete(i.codes, code)
+
+	if pending.id == from {
+		return from, true, nil
+	}
+	for name, id := range i.directory.IDs {
+		if id == from {
+			i.directory.IDs[name] = pending.id
+		}
+	}
+
+	return pending.id, true, i.save()
+}
+
+// save persists the directory to disk
+func (i *Handler) save() error {
+	err := persist.Save(fmt.Sprintf("%s.json", i.path), i.directory)
+	if err != nil {
+		return fmt.Errorf("save identities failed: %v", err)
+	}
+
+	return nil
+}
+
+// Load recovers the directory from disk
+func Load(path string) (*Handler, error) {
+	var d directory
+
+	err := persist.Load(fmt.Sprintf("%s.json", path), &d)
+	if err != nil || d.IDs == nil {
+		d = directory{IDs: make(map[string]int64)}
+	}
+	if d.Next < Offset {
+		d.Next = Offset
+	}
+
+	// Directories from before linking had one account per ID
+	if d.Homes == nil {
+		d.Homes = make(map[int64]string)
+		for name, id := range d.IDs {
+			d.Homes[id] = name
+		}
+	}
+
+	return &Handler{
+		directory: d,
+		codes:     make(map[string]linkCode),
+		failed:    make(map[int64]failures),
+		path:      path,
+		now:       time.Now,
+	}, err
+}
@@ -0,0 +1,146 @@
+package identity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	obj, _ := Load("/tmp/identity")
+	obj.directory = directory{Next: Offset, IDs: make(map[string]int64), Homes: make(map[int64]string)}
+
+	first, err := obj.Resolve("slack", "T1/U1")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if first != Offset {
+		t.Errorf("Expected the first ID to be %d, got %d", Offset, first)
+	}
+
+	second, _ := obj.Resolve("slack", "T1/U2")
+	again, _ := obj.Resolve("slack", "T1/U1")
+	if second != Offset+1 || again != first {
+		t.Errorf("Expected stable IDs, got %d, %d and %d", first, second, again)
+	}
+
+	transport, account, ok := obj.Lookup(second)
+	if !ok || transport != "slack" || account != "T1/U2" {
+		t.Errorf("Expected slack T1/U2, got %s %s %v", transport, account, ok)
+	}
+	if _, _, ok := obj.Lookup(12345); ok {
+		t.Errorf("Expected Telegram IDs to be unknown")
+	}
+
+	obj, err = Load("/tmp/identity")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if id, _ := obj.Resolve("slack", "T1/U2"); id != second {
+		t.Errorf("Expected IDs to be saved, got %d", id)
+	}
+	if id, _ := obj.Resolve("slack", "T1/U3"); id != Offset+2 {
+		t.Errorf("Expected the next ID to be saved, got %d", id)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	obj, _ := Load("/tmp/identity-claim")
+	obj.directory = directory{Next: Offset, IDs: make(map[string]int64), Homes: make(map[int64]string)}
+
+	id, err := obj.Claim("telegram", "12345", 12345)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if id != 12345 {
+		t.Errorf("Expected the chat ID to be kept, got %d", id)
+	}
+	transport, account, ok := obj.Lookup(12345)
+	if !ok || transport != "telegram" || account != "12345" {
+		t.Errorf("Expected telegram 12345, got %s %s %v", transport, account, ok)
+	}
+
+	obj.directory.IDs["telegram:12345"] = Offset
+	if id, _ := obj.Claim("telegram", "12345", 12345); id != Offset {
+		t.Errorf("Expected a linked chat to keep its link, got %d", id)
+	}
+}
+
+func TestLink(t *testing.T) {
+	obj, _ := Load("/tmp/identity-link")
+	obj.directory = directory{Next: Offset, IDs: make(map[string]int64), Homes: make(map[int64]string)}
+	now := time.Unix(1500000000, 0)
+	obj.now = func() time.Time { return now }
+
+	obj.Claim("telegram", "12345", 12345)
+	slackID, _ := obj.Resolve("slack", "T1/U1")
+
+	code, err := obj.NewCode(12345)
+	if err != nil || len(code) != 8 {
+		t.Fatalf("Expected an eight digit code, got %q %+v", code, err)
+	}
+	if _, ok, _ := obj.Redeem("not a code", slackID); ok {
+		t.Errorf("Expected an unknown code to be refused")
+	}
+
+	id, ok, err := obj.Redeem(" "+code+" ", slackID)
+	if err != nil || !ok || id != 12345 {
+		t.Errorf("Expected to link to 12345, got %d %v %+v", id, ok, err)
+	}
+	if id, _ := obj.Resolve("slack", "T1/U1"); id != 12345 {
+		t.Errorf("Expected the slack account to be linked, got %d", id)
+	}
+	if transport, account, _ := obj.Lookup(12345); transport != "telegram" || account != "12345" {
+		t.Errorf("Expected the home to stay on telegram, got %s %s", transport, account)
+	}
+	if _, ok, _ := obj.Redeem(code, slackID); ok {
+		t.Errorf("Expected codes to only work once")
+	}
+
+	code, _ = obj.NewCode(12345)
+	now = now.Add(CodeTimeout + time.Second)
+	if _, ok, _ := obj.Redeem(code, slackID); ok {
+		t.Errorf("Expected codes to expire")
+	}
+
+	obj, _ = Load("/tmp/identity-link")
+	if id, _ := obj.Resolve("slack", "T1/U1"); id != 12345 {
+		t.Errorf("Expected links to be saved, got %d", id)
+	}
+}
+
+func TestRedeemLocked(t *testing.T) {
+	obj, _ := Load("/tmp/identity-locked")
+	obj.directory = directory{Next: Offset, IDs: make(map[string]int64), Homes: make(map[int64]string)}
+	now := time.Unix(1500000000, 0)
+	obj.now = func() time.Time { return now }
+
+	code, _ := obj.NewCode(12345)
+	for n := 0; n < MaxFailures; n++ {
+		if _, ok, err := obj.Redeem("00000000", 1); ok || err != nil {
+			t.Errorf("Expected a wrong code to be refused, got %v %+v", ok, err)
+		}
+	}
+	if _, ok, err := obj.Redeem(code, 1); ok || err != ErrLocked {
+		t.Errorf("Expected the user to be locked out, got %v %+v", ok, err)
+	}
+	if id, ok, err := obj.Redeem(code, 2); !ok || err != nil || id != 12345 {
+		t.Errorf("Expected other users to still link, got %d %v %+v", id, ok, err)
+	}
+
+	now = now.Add(CodeTimeout)
+	code, _ = obj.NewCode(12345)
+	if id, ok, err := obj.Redeem(code, 1); !ok || err != nil || id != 12345 {
+		t.Errorf("Expected linking to unlock, got %d %v %+v", id, ok, err)
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	obj, _ := Load("/tmp/identity-migrate")
+	obj.directory = directory{Next: Offset + 1, IDs: map[string]int64{"slack:T1/U1": Offset}}
+	obj.save()
+
+	obj, _ = Load("/tmp/identity-migrate")
+	if transport, account, ok := obj.Lookup(Offset); !ok || transport != "slack" || account != "T1/U1" {
+		t.Errorf("Expected slack T1/U1, got %s %s %v", transport, account, ok)
+	}
+}
@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matryer/persist"
+)
+
+// Dictionary keeps track of the different names the community uses for the same thing
+type Dictionary struct {
+	aliases   map[string][]string
+	canonical map[string]string
+}
+
+// NewDictionary creates a dictionary from a map of canonical terms to their aliases
+func NewDictionary(groups map[string][]string) *Dictionary {
+	d := &Dictionary{
+		aliases:   make(map[string][]string),
+		canonical: make(map[string]string),
+	}
+
+	// Sort so an alias listed under two terms always resolves the same way
+	terms := make([]string, 0)
+	for term := range groups {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	for _, raw := range terms {
+		aliases := groups[raw]
+		term := strings.ToLower(strings.TrimSpace(raw))
+		if term == "" {
+			continue
+		}
+
+		d.canonical[term] = term
+		d.aliases[term] = append(d.aliases[term], term)
+		for _, alias := range aliases {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+			if _, ok := d.canonical[alias]; ok || alias == "" {
+				continue
+			}
+			d.canonical[alias] = term
+			d.aliases[term] = append(d.aliases[term], alias)
+		}
+	}
+
+	return d
+}
+
+// LoadDictionary reads the synonym dictionary from disk
+// The file is a JSON object of canonical terms to a list of aliases
+func LoadDictionary(path string) (*Dictionary, error) {
+	var groups map[string][]string
+
+	err := persist.Load(path, &groups)
+	if err != nil {
+		return NewDictionary(nil), fmt.Errorf("load dictionary failed: %v", err)
+	}
+
+	return NewDictionary(groups), nil
+}
+
+// Canonical returns the canonical term for a given keyword
+func (d *Dictionary) Canonical(keyword string) string {
+	keyword = strings.ToLower(keyword)
+	if d == nil {
+		return keyword
+	}
+
+	if term, ok := d.canonical[keyword]; ok {
+		return term
+	}
+	return keyword
+}
+
+// Expand returns the keyword followed by all of its aliases
+func (d *Dictionary) Expand(keyword string) []string {
+	keyword = strings.ToLower(keyword)
+	terms := []string{keyword}
+	if d == nil {
+		return terms
+	}
+
+	for _, alias := range d.aliases[d.Canonical(keyword)] {
+		if alias != keyword {
+			terms = append(terms, alias)
+		}
+	}
+	return terms
+}
+
+// Size returns the number of terms known to the dictionary
+func (d *Dictionary) Size() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.canonical)
+}
@@ -0,0 +1,59 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDictionary(t *testing.T) {
+	dict := NewDictionary(map[string][]string{
+		"GMK Olivia": {"olivia", "GMK Olivia++"},
+		"hhkb":       {"happy hacking keyboard"},
+	})
+
+	if dict.Size() != 5 {
+		t.Errorf("Expected 5 terms, got %d", dict.Size())
+	}
+
+	if term := dict.Canonical("Olivia"); term != "gmk olivia" {
+		t.Errorf("Expected gmk olivia, got %q", term)
+	}
+
+	if term := dict.Canonical("tada68"); term != "tada68" {
+		t.Errorf("Expected tada68, got %q", term)
+	}
+
+	expected := []string{"olivia", "gmk olivia", "gmk olivia++"}
+	if terms := dict.Expand("olivia"); !reflect.DeepEqual(terms, expected) {
+		t.Errorf("Expected %q, got %q", expected, terms)
+	}
+
+	expected = []string{"tada68"}
+	if terms := dict.Expand("tada68"); !reflect.DeepEqual(terms, expected) {
+		t.Errorf("Expected %q, got %q", expected, terms)
+	}
+}
+
+func TestDictionaryNil(t *testing.T) {
+	var dict *Dictionary
+
+	if dict.Size() != 0 {
+		t.Errorf("Expected 0 terms, got %d", dict.Size())
+	}
+
+	expected := []string{"hhkb"}
+	if terms := dict.Expand("HHKB"); !reflect.DeepEqual(terms, expected) {
+		t.Errorf("Expected %q, got %q", expected, terms)
+	}
+}
+
+func TestLoadDictionaryMissing(t *testing.T) {
+	dict, err := LoadDictionary("/tmp/foo/missing.json")
+
+	if err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+	if dict.Size() != 0 {
+		t.Errorf("Expected an empty dictionary, got %d terms", dict.Size())
+	}
+}
@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Sold is the flair for a post that is no longer available
+	Sold = "sold"
+	// Meta is the flair for a post about the subreddit itself
+	Meta = "meta"
+)
+
+var flairLookup = map[string]string{
+	"selling":        Selling,
+	"buying":         Buying,
+	"trading":        Trading,
+	"vendor":         Vendor,
+	"artisan":        Artisan,
+	"group buy":      GroupBuy,
+	"interest check": InterestCheck,
+	"giveaway":       Giveaway,
+	"sold":           Sold,
+	"purchased":      Sold,
+	"meta":           Meta,
+}
+
+// [COUNTRY-STATE] Something
+var regionRex = regexp.MustCompile(`^\[([A-Za-z]{2})(?:-\w+)?\]`)
+
+// regionCodes are the country codes a leading tag must have to be a region,
+// so tags like [WTS] or [FS] aren't mistaken for one
+var regionCodes = strings.Fields(`EU UK
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ
+BL BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR
+CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR
+GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU
+ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ
+LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ
+MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF
+PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI
+SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR
+TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`)
+
+// isRegion checks if a leading tag is a country code
+func isRegion(code string) bool {
+	for _, c := range regionCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// [ANYTHING]
+var tagRex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Classify returns the type, content, and error using both the title and link flair
+// The title is preferred, with the flair used when the title is not parsable
+func Classify(title, flair string) (*ParsedPost, error) {
+	flairType := flairLookup[strings.ToLower(strings.TrimSpace(flair))]
+
+	if flairType == Sold {
+		return nil, fmt.Errorf("already sold: %s", title)
+	}
+
+	item, err := ParseTitle(title)
+	if err == nil {
+		item.Flair = flairType
+		return item, nil
+	}
+
+	if flairType == "" || flairType == Meta {
+		return nil, err
+	}
+
+	item = &ParsedPost{
+		Type:     flairType,
+		Contents: strings.TrimSpace(tagRex.ReplaceAllString(title, " ")),
+		Flair:    flairType,
+		Fallback: true,
+	}
+	if m := regionRex.FindStringSubmatch(title); m != nil && isRegion(strings.ToUpper(m[1])) {
+		item.Region = strings.ToUpper(m[1])
+	}
+
+	return item, nil
+}
+
+// Disagrees checks if the link flair contradicts the type from the title
+func (p *ParsedPost) Disagrees() bool {
+	switch p.Flair {
+	case "", p.Type:
+		return false
+	case Trading:
+		return !p.Trade
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package matcher
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	totalTests := []struct {
+		title string
+		flair string
+		out   *ParsedPost
+		err   error
+	}{
+		{
+			"[US-TX] [H] Tada68 [W] PayPal",
+			"Selling",
+			&ParsedPost{
+				Type:     Selling,
+				Contents: "Tada68",
+				Region:   "US",
+				Have:     "Tada68",
+				Want:     "PayPal",
+				Flair:    Selling,
+			},
+			nil,
+		},
+		{
+			"[US-TX] Tada68 for sale",
+			"Selling",
+			&ParsedPost{
+				Type:     Selling,
+				Contents: "Tada68 for sale",
+				Region:   "US",
+				Flair:    Selling,
+				Fallback: true,
+			},
+			nil,
+		},
+		{
+			"[WTS] GMK Olivia",
+			"Selling",
+			&ParsedPost{
+				Type:     Selling,
+				Contents: "GMK Olivia",
+				Flair:    Selling,
+				Fallback: true,
+			},
+			nil,
+		},
+		{
+			"[CA-ON] Tada68 for sale",
+			"Selling",
+			&ParsedPost{
+				Type:     Selling,
+				Contents: "Tada68 for sale",
+				Region:   "CA",
+				Flair:    Selling,
+				Fallback: true,
+			},
+			nil,
+		},
+		{
+			"GMK Olivia Restock [NovelKeys]",
+			"Group Buy",
+			&ParsedPost{
+				Type:     GroupBuy,
+				Contents: "GMK Olivia Restock",
+				Flair:    GroupBuy,
+				Fallback: true,
+			},
+			nil,
+		},
+		{
+			"[US-TX] [H] Tada68 [W] PayPal",
+			"Sold",
+			nil,
+			fmt.Errorf("already sold: [US-TX] [H] Tada68 [W] PayPal"),
+		},
+		{
+			"May Confirmed Trade Thread",
+			"Meta",
+			nil,
+			fmt.Errorf("not parsable: May Confirmed Trade Thread"),
+		},
+		{
+			"Something odd",
+			"",
+			nil,
+			fmt.Errorf("not parsable: Something odd"),
+		},
+	}
+
+	for _, tt := range totalTests {
+		out, err := Classify(tt.title, tt.flair)
+
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Expected Out %+v, got %+v", tt.out, out)
+		}
+		if !reflect.DeepEqual(err, tt.err) {
+			t.Errorf("Expected Err %q, got %q", tt.err, err)
+		}
+	}
+}
+
+func TestDisagrees(t *testing.T) {
+	totalTests := []struct {
+		in  *ParsedPost
+		out bool
+	}{
+		{&ParsedPost{Type: Selling}, false},
+		{&ParsedPost{Type: Selling, Flair: Selling}, false},
+		{&ParsedPost{Type: Selling, Flair: Trading, Trade: true}, false},
+		{&ParsedPost{Type: Selling, Flair: Trading}, true},
+		{&ParsedPost{Type: Buying, Flair: Selling}, true},
+		{&ParsedPost{Type: Vendor, Flair: Meta}, true},
+	}
+
+	for _, tt := range totalTests {
+		if out := tt.in.Disagrees(); out != tt.out {
+			t.Errorf("Expected %+v to be %v, got %v", tt.in, tt.out, out)
+		}
+	}
+}
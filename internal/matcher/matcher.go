@@ -11,6 +11,8 @@ var (
 	Buying = "buying"
 	// Selling is for a post that is selling something
 	Selling = "selling"
+	// Trading is for a post that is trading something for something else
+	Trading = "trading"
 	// Vendor is for a post from a specific vendor
 	Vendor = "vendor"
 	// Artisan is for a post from a specific artisan
@@ -22,7 +24,7 @@ var (
 	// Giveaway is for a post about giving away something
 	Giveaway = "giveaway"
 	// Types is a list of all types
-	Types = []string{Buying, Selling, Vendor, Artisan, GroupBuy, InterestCheck, Giveaway}
+	Types = []string{Buying, Selling, Trading, Vendor, Artisan, GroupBuy, InterestCheck, Giveaway}
 )
 
 var typeLookup = map[string]string{
@@ -38,6 +40,24 @@ type ParsedPost struct {
 	Type     string
 	Contents string
 	Region   string
+	// Have is what the poster is offering
+	Have string
+	// Want is what the poster is looking for
+	Want string
+	// Trade is set when the poster accepts items in exchange
+	Trade bool
+	// Flair is the type from the post's link flair
+	Flair string
+	// Fallback is set when the title was not parsable and the flair was used
+	Fallback bool
+}
+
+// Target is a subscription type and the part of a post it matches against
+type Target struct {
+	Type     string
+	Contents string
+	// Trades limits matching to subscriptions that include trades
+	Trades bool
 }
 
 // [TYPE] Something
@@ -46,7 +66,9 @@ var nonSalesRex = regexp.MustCompile(`(?i)^\[(vendor|artisan|gb|ic|giveaway)\]\s
 // [COUNTRY-STATE] [H] Something [W] Something else
 var salesRex = regexp.MustCompile(`(?i)^\[(\w+)(?:-\w+)?\]\s*\[H\]\s*(.*)\s*\[W\]\s*(.*)$`)
 
-var moneyRex = regexp.MustCompile(`(?i)(paypal|cash)`)
+var moneyRex = regexp.MustCompile(`(?i)(paypal|cash|money|venmo)`)
+
+var tradeRex = regexp.MustCompile(`(?i)\btrades?\b`)
 
 // ParseTitle returns the a type, content, and error
 func ParseTitle(title string) (*ParsedPost, error) {
@@ -61,36 +83,119 @@ func ParseTitle(title string) (*ParsedPost, error) {
 	if sales == nil {
 		return nil, fmt.Errorf("not parsable: %s", title)
 	}
-	region, have, want := sales[1], sales[2], sales[3]
+	region := sales[1]
+	have, want := strings.TrimSpace(sales[2]), strings.TrimSpace(sales[3])
 
-	// Ensure it's for sale
-	if !moneyRex.MatchString(want) {
+	// Money for items
+	if moneyRex.MatchString(have) {
 		return &ParsedPost{
 			Type:     Buying,
-			Contents: strings.TrimSpace(want),
+			Contents: want,
 			Region:   region,
+			Have:     have,
+			Want:     want,
+			Trade:    tradeRex.MatchString(have),
 		}, nil
 	}
 
-	// Return the things for sale
+	// Items for money
+	if moneyRex.MatchString(want) {
+		return &ParsedPost{
+			Type:     Selling,
+			Contents: have,
+			Region:   region,
+			Have:     have,
+			Want:     want,
+			Trade:    tradeRex.MatchString(want),
+		}, nil
+	}
+
+	// Items for items
 	return &ParsedPost{
-		Type:     Selling,
-		Contents: strings.TrimSpace(have),
+		Type:     Trading,
+		Contents: fmt.Sprintf("%s / %s", have, want),
 		Region:   region,
+		Have:     have,
+		Want:     want,
+		Trade:    true,
 	}, nil
 }
 
+// Targets returns the subscription types that a post should be matched against
+// Trades are offered to buying subscriptions (want side), as they always were, and to
+// selling subscriptions that include trades (have side), and sales that accept trades
+// are offered to trading subscriptions
+func (p *ParsedPost) Targets() []Target {
+	targets := []Target{{Type: p.Type, Contents: p.Contents}}
+
+	switch {
+	case p.Type == Trading:
+		targets = append(targets,
+			Target{Type: Selling, Contents: p.Have, Trades: true},
+			Target{Type: Buying, Contents: p.Want},
+		)
+	case p.Trade:
+		targets = append(targets, Target{Type: Trading, Contents: p.Contents})
+	}
+
+	return targets
+}
+
+// Match is a keyword found in a post
+type Match struct {
+	// Keyword is the watched keyword
+	Keyword string
+	// Canonical is the dictionary term for the keyword
+	Canonical string
+	// Term is the keyword or alias that was found
+	Term string
+}
+
 // FindMatching returns list of keywords that match a given title/description
-func FindMatching(keywords []string, title, desc string) []string {
-	matches := []string{}
+// Each keyword is expanded to its aliases using the dictionary, and only
+// the parts of the description allowed by the keyword's scope are searched
+func FindMatching(dict *Dictionary, keywords []string, title, desc string) []Match {
+	matches := []Match{}
 	title = strings.ToLower(title)
-	desc = strings.ToLower(desc)
+	body := ParseSelfText(strings.ToLower(desc))
 
 	for _, keyword := range keywords {
-		if keyword == "*" || strings.Contains(title, keyword) || strings.Contains(desc, keyword) {
-			matches = append(matches, keyword)
+		sub := ParseSubscription(keyword)
+		if sub.Keyword == "*" {
+			matches = append(matches, Match{Keyword: keyword, Canonical: sub.Keyword, Term: sub.Keyword})
+			continue
+		}
+
+		texts := []string{}
+		if sub.Scope != ScopeItems {
+			texts = append(texts, title)
+		}
+		texts = append(texts, body.Search(sub.Scope))
+
+		if term, ok := findTerm(dict.Expand(sub.Keyword), texts...); ok {
+			matches = append(matches, Match{
+				Keyword:   keyword,
+				Canonical: dict.Canonical(sub.Keyword),
+				Term:      term,
+			})
 		}
 	}
 
 	return matches
 }
+
+// findTerm returns the longest term found in the first text that has any
+func findTerm(terms []string, texts ...string) (string, bool) {
+	for _, text := range texts {
+		found := ""
+		for _, term := range terms {
+			if len(term) > len(found) && strings.Contains(text, term) {
+				found = term
+			}
+		}
+		if found != "" {
+			return found, true
+		}
+	}
+	return "", false
+}
@@ -4,8 +4,21 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/unparsed"
 )
 
+// loadCorpus reads a corpus of unparsable titles saved by the bot
+func loadCorpus(t *testing.T, path string) []unparsed.Entry {
+	t.Helper()
+
+	corpus, err := unparsed.Load(path, 0)
+	if err != nil {
+		t.Fatalf("Unable to load corpus %s: %v", path, err)
+	}
+	return corpus.Recent(0)
+}
+
 func TestParseTitle(t *testing.T) {
 	totalTests := []struct {
 		in  string
@@ -18,6 +31,8 @@ func TestParseTitle(t *testing.T) {
 				Type:     Selling,
 				Contents: "PrimeCap / CM PBT L Cherry MX Blues",
 				Region:   "US",
+				Have:     "PrimeCap / CM PBT L Cherry MX Blues",
+				Want:     "Paypal, Local Cash",
 			},
 			nil,
 		},
@@ -27,6 +42,8 @@ func TestParseTitle(t *testing.T) {
 				Type:     Selling,
 				Contents: "BKE Redux Heavy, FC660C 45g Topre Domes, Leopold Keycaps Doubleshot PBT Dolch",
 				Region:   "CA",
+				Have:     "BKE Redux Heavy, FC660C 45g Topre Domes, Leopold Keycaps Doubleshot PBT Dolch",
+				Want:     "PayPal",
 			},
 			nil,
 		},
@@ -36,6 +53,9 @@ func TestParseTitle(t *testing.T) {
 				Type:     Selling,
 				Contents: "GMK Nautilus, Doomcaps, ETF, Brocaps",
 				Region:   "NO",
+				Have:     "GMK Nautilus, Doomcaps, ETF, Brocaps",
+				Want:     "PayPal, trades",
+				Trade:    true,
 			},
 			nil,
 		},
@@ -45,6 +65,8 @@ func TestParseTitle(t *testing.T) {
 				Type:     Buying,
 				Contents: "65g r7+ zealios, zeal stabs r2 or newer",
 				Region:   "US",
+				Have:     "PayPal, Local Cash",
+				Want:     "65g r7+ zealios, zeal stabs r2 or newer",
 			},
 			nil,
 		},
@@ -54,6 +76,20 @@ func TestParseTitle(t *testing.T) {
 				Type:     Buying,
 				Contents: "~60 alps orange or salmon",
 				Region:   "US",
+				Have:     "PayPal",
+				Want:     "~60 alps orange or salmon",
+			},
+			nil,
+		},
+		{
+			"[US-NY] [H] Tada68 [W] HHKB",
+			&ParsedPost{
+				Type:     Trading,
+				Contents: "Tada68 / HHKB",
+				Region:   "US",
+				Have:     "Tada68",
+				Want:     "HHKB",
+				Trade:    true,
 			},
 			nil,
 		},
@@ -84,7 +120,7 @@ func TestParseTitle(t *testing.T) {
 		out, err := ParseTitle(tt.in)
 
 		if !reflect.DeepEqual(out, tt.out) {
-			t.Errorf("Expected Out %q, got %q", tt.out, out)
+			t.Errorf("Expected Out %+v, got %+v", tt.out, out)
 		}
 		if !reflect.DeepEqual(err, tt.err) {
 			t.Errorf("Expected Err %q, got %q", tt.err, err)
@@ -92,34 +128,218 @@ func TestParseTitle(t *testing.T) {
 	}
 }
 
+// TestParseTitleCorpus keeps track of titles seen in the wild that the parser can't handle
+// When the parser learns a new format, add the title and its type to fixed
+func TestParseTitleCorpus(t *testing.T) {
+	fixed := map[string]string{}
+
+	entries := loadCorpus(t, "testdata/unparsed")
+	if len(entries) == 0 {
+		t.Fatalf("Expected corpus entries, got none")
+	}
+
+	for _, entry := range entries {
+		out, err := ParseTitle(entry.Title)
+
+		expected, ok := fixed[entry.Title]
+		if !ok && err == nil {
+			t.Errorf("Expected %q to be unparsable, got %+v", entry.Title, out)
+		}
+		if ok && (err != nil || out.Type != expected) {
+			t.Errorf("Expected %q to be %s, got %+v (%v)", entry.Title, expected, out, err)
+		}
+
+		out, err = Classify(entry.Title, entry.Flair)
+		if entry.Flair != "Meta" && (err != nil || out.Type == "") {
+			t.Errorf("Expected %q to be classified by flair %q, got %v", entry.Title, entry.Flair, err)
+		}
+	}
+}
+
+func TestTargets(t *testing.T) {
+	totalTests := []struct {
+		in  *ParsedPost
+		out []Target
+	}{
+		{
+			&ParsedPost{Type: Selling, Contents: "Tada68", Have: "Tada68", Want: "PayPal"},
+			[]Target{
+				{Type: Selling, Contents: "Tada68"},
+			},
+		},
+		{
+			&ParsedPost{Type: Selling, Contents: "Tada68", Have: "Tada68", Want: "PayPal, trades", Trade: true},
+			[]Target{
+				{Type: Selling, Contents: "Tada68"},
+				{Type: Trading, Contents: "Tada68"},
+			},
+		},
+		{
+			&ParsedPost{Type: Trading, Contents: "Tada68 / HHKB", Have: "Tada68", Want: "HHKB", Trade: true},
+			[]Target{
+				{Type: Trading, Contents: "Tada68 / HHKB"},
+				{Type: Selling, Contents: "Tada68", Trades: true},
+				{Type: Buying, Contents: "HHKB"},
+			},
+		},
+		{
+			&ParsedPost{Type: Vendor, Contents: "NovelKeys Restocks"},
+			[]Target{
+				{Type: Vendor, Contents: "NovelKeys Restocks"},
+			},
+		},
+	}
+
+	for _, tt := range totalTests {
+		out := tt.in.Targets()
+
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Expected %+v, got %+v", tt.out, out)
+		}
+	}
+}
+
 func TestFindMatching(t *testing.T) {
 	sale := "PrimeCap / CM PBT L Cherry MX Blues"
 	description := "something something timestamp something tao hao"
 
 	totalTests := []struct {
 		in  []string
-		out []string
+		out []Match
 	}{
 		{
 			[]string{"brocap"},
-			[]string{},
+			[]Match{},
 		},
 		{
 			[]string{"primecap", "brocap"},
-			[]string{"primecap"},
+			[]Match{
+				{Keyword: "primecap", Canonical: "primecap", Term: "primecap"},
+			},
 		},
 		{
 			[]string{"tao hao", "primecap"},
-			[]string{"tao hao", "primecap"},
+			[]Match{
+				{Keyword: "primecap", Canonical: "primecap", Term: "primecap"},
+			},
+		},
+		{
+			[]string{"tao hao in:any", "primecap"},
+			[]Match{
+				{Keyword: "tao hao in:any", Canonical: "tao hao", Term: "tao hao"},
+				{Keyword: "primecap", Canonical: "primecap", Term: "primecap"},
+			},
 		},
 		{
 			[]string{"*"},
-			[]string{"*"},
+			[]Match{
+				{Keyword: "*", Canonical: "*", Term: "*"},
+			},
+		},
+		{
+			[]string{"primecap trades", "trades"},
+			[]Match{
+				{Keyword: "primecap trades", Canonical: "primecap", Term: "primecap"},
+				{Keyword: "trades", Canonical: "*", Term: "*"},
+			},
+		},
+	}
+
+	for _, tt := range totalTests {
+		out := FindMatching(nil, tt.in, sale, description)
+
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Expected %q, got %q", tt.out, out)
+		}
+	}
+}
+
+func TestFindMatchingScope(t *testing.T) {
+	sale := "Tada68, Tofu, Keycaps"
+	description := `Timestamp: https://imgur.com/a/foo
+
+| Item | Price |
+|---|---|
+| KBD67 | $200 |
+| GMK Olivia | $300 |
+
+Comes with a case that also fits a tada68 or a hhkb
+
+* Holy Pandas x70 - $100 shipped
+
+Shipping is CONUS only, add $5 for hhkb boxes`
+
+	totalTests := []struct {
+		in  []string
+		out []Match
+	}{
+		{
+			[]string{"hhkb", "hhkb in:items", "kbd67", "kbd67 in:items"},
+			[]Match{
+				{Keyword: "kbd67 in:items", Canonical: "kbd67", Term: "kbd67"},
+			},
+		},
+		{
+			[]string{"hhkb in:any", "holy pandas in:items", "tada68 in:items"},
+			[]Match{
+				{Keyword: "hhkb in:any", Canonical: "hhkb", Term: "hhkb"},
+				{Keyword: "holy pandas in:items", Canonical: "holy pandas", Term: "holy pandas"},
+			},
+		},
+		{
+			[]string{"tada68", "tada68 in:title", "imgur in:items"},
+			[]Match{
+				{Keyword: "tada68", Canonical: "tada68", Term: "tada68"},
+				{Keyword: "tada68 in:title", Canonical: "tada68", Term: "tada68"},
+			},
+		},
+	}
+
+	for _, tt := range totalTests {
+		out := FindMatching(nil, tt.in, sale, description)
+
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Expected %q, got %q", tt.out, out)
+		}
+	}
+}
+
+func TestFindMatchingAliases(t *testing.T) {
+	dict := NewDictionary(map[string][]string{
+		"zealios": {"zeal", "zealpc zealios"},
+		"hhkb":    {"happy hacking keyboard"},
+	})
+
+	totalTests := []struct {
+		in    []string
+		title string
+		out   []Match
+	}{
+		{
+			[]string{"hhkb"},
+			"Happy Hacking Keyboard Pro 2",
+			[]Match{
+				{Keyword: "hhkb", Canonical: "hhkb", Term: "happy hacking keyboard"},
+			},
+		},
+		{
+			[]string{"happy hacking keyboard"},
+			"HHKB Type-S",
+			[]Match{
+				{Keyword: "happy hacking keyboard", Canonical: "hhkb", Term: "hhkb"},
+			},
+		},
+		{
+			[]string{"zealpc zealios", "hhkb"},
+			"70x Zeal 67g",
+			[]Match{
+				{Keyword: "zealpc zealios", Canonical: "zealios", Term: "zeal"},
+			},
 		},
 	}
 
 	for _, tt := range totalTests {
-		out := FindMatching(tt.in, sale, description)
+		out := FindMatching(dict, tt.in, tt.title, "")
 
 		if !reflect.DeepEqual(out, tt.out) {
 			t.Errorf("Expected %q, got %q", tt.out, out)
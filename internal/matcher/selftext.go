@@ -0,0 +1,183 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SelfText is the body of a post split into sections
+type SelfText struct {
+	// Items are rows from item tables and bullet lists
+	Items []string
+	// Timestamps are lines linking to timestamp photos
+	Timestamps []string
+	// Shipping are notes about shipping and payment
+	Shipping []string
+	// Other is everything else
+	Other []string
+}
+
+// | Item | Price |
+var tableRex = regexp.MustCompile(`^\|?.+\|.+$`)
+
+// |---|:---:|
+var tableSeparatorRex = regexp.MustCompile(`^[\s|:-]+$`)
+
+// * Item, - Item, + Item, 1. Item
+var bulletRex = regexp.MustCompile(`^([*+-]|\d+[.)])\s+`)
+
+var timestampRex = regexp.MustCompile(`(?i)(timestamps?|imgur\.com|i\.redd\.it)`)
+
+// $100, $ 1,200.50
+var priceRex = regexp.MustCompile(`\$\s?\d[\d,]*(?:\.\d{2})?`)
+
+// imgur.com/AbCdE, i.imgur.com/AbCdE.jpg, but not albums like imgur.com/a/AbCdE
+var imgurRex = regexp.MustCompile(`https?://(?:i\.|m\.)?imgur\.com/([A-Za-z0-9]{5,})(?:\.(?:jpe?g|png|gif))?(?:[^/A-Za-z0-9.]|$)`)
+
+// i.redd.it/abc123.jpg
+var reddItRex = regexp.MustCompile(`https?://i\.redd\.it/[A-Za-z0-9]+\.(?:jpe?g|png|gif)\b`)
+
+// Table pipes and markdown emphasis
+var markupRex = regexp.MustCompile(`[|*_~#>]+`)
+
+var shippingRex = regexp.MustCompile(`(?i)(shipping|shipped|\bship\b|conus|paypal|\bg&s\b|\bf&f\b)`)
+
+// ParseSelfText splits a mechmarket post body into sections
+func ParseSelfText(text string) *SelfText {
+	s := &SelfText{}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || tableSeparatorRex.MatchString(line) {
+			continue
+		}
+
+		switch {
+		case tableRex.MatchString(line), bulletRex.MatchString(line):
+			s.Items = append(s.Items, line)
+		case timestampRex.MatchString(line):
+			s.Timestamps = append(s.Timestamps, line)
+		case shippingRex.MatchString(line):
+			s.Shipping = append(s.Shipping, line)
+		default:
+			s.Other = append(s.Other, line)
+		}
+	}
+
+	return s
+}
+
+// Search returns the text to search for a given scope
+func (s *SelfText) Search(scope string) string {
+	switch scope {
+	case ScopeItems:
+		return strings.Join(s.Items, "\n")
+	case ScopeAny:
+		sections := [][]string{s.Items, s.Timestamps, s.Shipping, s.Other}
+		lines := []string{}
+		for _, section := range sections {
+			lines = append(lines, section...)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
+// Price returns the first price in the items, or anywhere else in the post
+func (s *SelfText) Price() string {
+	for _, text := range []string{s.Search(ScopeItems), s.Search(ScopeAny)} {
+		if price := priceRex.FindString(text); price != "" {
+			return strings.Replace(price, " ", "", -1)
+		}
+	}
+	return ""
+}
+
+// Image returns a direct link to the first imgur or i.redd.it image, looking
+// at the timestamps first
+func (s *SelfText) Image() string {
+	for _, text := range []string{strings.Join(s.Timestamps, "\n"), s.Search(ScopeAny)} {
+		imgur := imgurRex.FindStringSubmatchIndex(text)
+		reddIt := reddItRex.FindStringIndex(text)
+		switch {
+		case reddIt != nil && (imgur == nil || reddIt[0] < imgur[0]):
+			return text[reddIt[0]:reddIt[1]]
+		case imgur != nil:
+			return fmt.Sprintf("https://i.imgur.com/%s.jpg", text[imgur[2]:imgur[3]])
+		}
+	}
+	return ""
+}
+
+// flatten joins lines into one line of text without markup
+func flatten(lines []string) string {
+	return strings.Join(strings.Fields(markupRex.ReplaceAllString(strings.Join(lines, " / "), " ")), " ")
+}
+
+// Excerpt returns the start of the items, or the rest of the post without
+// items, cut at a word to at most limit characters
+func (s *SelfText) Excerpt(limit int) string {
+	lines := s.Items
+	if len(lines) == 0 {
+		lines = s.Other
+	}
+
+	runes := []rune(flatten(lines))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return cut(runes[:limit], false, true)
+}
+
+// ExcerptAround returns the text around the first match of a pattern, cut at
+// words to at most limit characters, or the start of the post without a match
+func (s *SelfText) ExcerptAround(pattern *regexp.Regexp, limit int) string {
+	text := flatten(append(append(append([]string{}, s.Items...), s.Other...), s.Shipping...))
+	loc := []int(nil)
+	if pattern != nil {
+		loc = pattern.FindStringIndex(text)
+	}
+	if loc == nil {
+		return s.Excerpt(limit)
+	}
+
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	// Center the match, keeping the window inside the text
+	start := utf8.RuneCountInString(text[:loc[0]])
+	length := utf8.RuneCountInString(text[loc[0]:loc[1]])
+	start -= (limit - length) / 2
+	if start < 0 {
+		start = 0
+	}
+	end := start + limit
+	if end > len(runes) {
+		end = len(runes)
+		start = end - limit
+	}
+
+	return cut(runes[start:end], start > 0, end < len(runes))
+}
+
+// cut drops the partial words at the ends of a window that was cut from longer text
+func cut(runes []rune, head, tail bool) string {
+	text := string(runes)
+	if tail {
+		if i := strings.LastIndex(text, " "); i > 0 {
+			text = text[:i]
+		}
+		text += "…"
+	}
+	if head {
+		if i := strings.Index(text, " "); i >= 0 {
+			text = text[i+1:]
+		}
+		text = "…" + text
+	}
+	return text
+}
@@ -0,0 +1,106 @@
+package matcher
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseSelfText(t *testing.T) {
+	text := `Timestamps: https://imgur.com/a/foo
+
+Item | Price
+:--|--:
+Tada68 | $100
+KBD67 | $200
+
+- Holy Pandas x70
+1. Zealios 67g
+
+Comes with a case.
+Shipping is CONUS only, PayPal G&S`
+
+	expected := &SelfText{
+		Items:      []string{"Item | Price", "Tada68 | $100", "KBD67 | $200", "- Holy Pandas x70", "1. Zealios 67g"},
+		Timestamps: []string{"Timestamps: https://imgur.com/a/foo"},
+		Shipping:   []string{"Shipping is CONUS only, PayPal G&S"},
+		Other:      []string{"Comes with a case."},
+	}
+
+	actual := ParseSelfText(text)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+
+	if search := actual.Search(ScopeTitle); search != "" {
+		t.Errorf("Expected no text for title scope, got %q", search)
+	}
+
+	expectedSearch := "Item | Price\nTada68 | $100\nKBD67 | $200\n- Holy Pandas x70\n1. Zealios 67g"
+	if search := actual.Search(ScopeItems); search != expectedSearch {
+		t.Errorf("Expected %q, got %q", expectedSearch, search)
+	}
+}
+
+func TestPrice(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Tada68 | $ 100\nShipping is $15": "$100",
+		"Shipping is $15":                 "$15",
+		"- GMK Olivia $1,200.50":          "$1,200.50",
+		"Price is negotiable":             "",
+	} {
+		if actual := ParseSelfText(text).Price(); actual != expected {
+			t.Errorf("Expected %q to equal %q", actual, expected)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	for _, test := range []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"Item | Price\n:--|--:\nTada68 | $100\nComes with a case", 100, "Item Price / Tada68 $100"},
+		{"Comes with **a case** and\ncables", 100, "Comes with a case and / cables"},
+		{"- Tada68 with a case", 12, "- Tada68…"},
+		{"", 10, ""},
+	} {
+		if actual := ParseSelfText(test.text).Excerpt(test.limit); actual != test.expected {
+			t.Errorf("Expected %q to equal %q", actual, test.expected)
+		}
+	}
+}
+
+func TestImage(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Timestamps: https://imgur.com/a/album1 and [this](https://imgur.com/AbCdE1)": "https://i.imgur.com/AbCdE1.jpg",
+		"[Timestamp](https://i.redd.it/abc123.png)\nhttps://i.imgur.com/XyZ12.png":    "https://i.redd.it/abc123.png",
+		"- Tada68 https://m.imgur.com/QwErT":                                          "https://i.imgur.com/QwErT.jpg",
+		"Timestamps: https://imgur.com/gallery/foo":                                   "",
+		"No pictures": "",
+	} {
+		if actual := ParseSelfText(text).Image(); actual != expected {
+			t.Errorf("Expected %q to equal %q", actual, expected)
+		}
+	}
+}
+
+func TestExcerptAround(t *testing.T) {
+	text := "Item | Price\n:--|--:\n" + strings.Repeat("filler words ", 10) + "| Tada68 with **case** | $100\n" + strings.Repeat("more words ", 10)
+	for _, test := range []struct {
+		pattern  *regexp.Regexp
+		limit    int
+		expected string
+	}{
+		{regexp.MustCompile("(?i)(tada68)"), 40, "…filler words Tada68 with case $100…"},
+		{regexp.MustCompile("(?i)(tada68)"), 1000, "Item Price / " + strings.Repeat("filler words ", 10) + "Tada68 with case $100 / " + strings.TrimSpace(strings.Repeat("more words ", 10))},
+		{regexp.MustCompile("(?i)(kbd67)"), 20, "Item Price / filler…"},
+		{nil, 20, "Item Price / filler…"},
+	} {
+		if actual := ParseSelfText(text).ExcerptAround(test.pattern, test.limit); actual != test.expected {
+			t.Errorf("Expected %q to equal %q", actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package matcher
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ScopeTitle only matches the title of a post
+	ScopeTitle = "title"
+	// ScopeItems only matches the item tables and lists in the post body
+	ScopeItems = "items"
+	// ScopeAny matches the title or anywhere in the post body
+	ScopeAny = "any"
+)
+
+// Subscription is a watched keyword along with its options
+// e.g. "tada68 trades" watches for tada68 and includes trade posts
+type Subscription struct {
+	Keyword string
+	// Trades includes trade posts in selling and buying subscriptions
+	Trades bool
+	// Scope is where in the post to look for the keyword
+	Scope string
+	// MinRep is the minimum number of trades the author must have
+	MinRep int
+	// Once removes the subscription after the first match
+	Once bool
+	// For is how long the subscription lasts (e.g. 14d)
+	For string
+}
+
+// for:14d
+var forRex = regexp.MustCompile(`^for:([1-9]\d*[mhdw])$`)
+
+// minrep:10
+var minRepRex = regexp.MustCompile(`^minrep:(\d+)$`)
+
+// Trades: 42
+var repRex = regexp.MustCompile(`(?i)trades?:\s*(\d+)`)
+
+// ParseReputation returns the number of confirmed trades from an author's flair
+func ParseReputation(flair string) (int, bool) {
+	m := repRex.FindStringSubmatch(flair)
+	if m == nil {
+		return 0, false
+	}
+
+	trades, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return trades, true
+}
+
+// ParseSubscription splits a watched keyword into the search term and its options
+// Options are only recognized at the end of the keyword
+func ParseSubscription(raw string) Subscription {
+	sub := Subscription{}
+	fields := strings.Fields(strings.ToLower(raw))
+
+	for len(fields) > 0 && sub.parseOption(fields[len(fields)-1]) {
+		fields = fields[:len(fields)-1]
+	}
+
+	sub.Keyword = strings.Join(fields, " ")
+	if sub.Keyword == "" {
+		sub.Keyword = "*"
+	}
+	if sub.Scope == "" {
+		sub.Scope = ScopeTitle
+	}
+
+	return sub
+}
+
+// parseOption applies a single option, returning false if it is not one
+func (s *Subscription) parseOption(field string) bool {
+	switch field {
+	case "trades":
+		if s.Trades {
+			return false
+		}
+		s.Trades = true
+	case "once":
+		if s.Once {
+			return false
+		}
+		s.Once = true
+	case "in:" + ScopeTitle, "in:" + ScopeItems, "in:" + ScopeAny:
+		if s.Scope != "" {
+			return false
+		}
+		s.Scope = strings.TrimPrefix(field, "in:")
+	default:
+		if m := forRex.FindStringSubmatch(field); m != nil && s.For == "" {
+			s.For = m[1]
+			return true
+		}
+
+		m := minRepRex.FindStringSubmatch(field)
+		if m == nil || s.MinRep != 0 {
+			return false
+		}
+		minRep, err := strconv.Atoi(m[1])
+		if err != nil || minRep == 0 {
+			return false
+		}
+		s.MinRep = minRep
+	}
+
+	return true
+}
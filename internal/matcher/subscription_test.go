@@ -0,0 +1,64 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSubscription(t *testing.T) {
+	totalTests := []struct {
+		in  string
+		out Subscription
+	}{
+		{"tada68", Subscription{Keyword: "tada68", Scope: ScopeTitle}},
+		{"", Subscription{Keyword: "*", Scope: ScopeTitle}},
+		{"*", Subscription{Keyword: "*", Scope: ScopeTitle}},
+		{"GMK Olivia trades", Subscription{Keyword: "gmk olivia", Trades: true, Scope: ScopeTitle}},
+		{"trades", Subscription{Keyword: "*", Trades: true, Scope: ScopeTitle}},
+		{"trades trades", Subscription{Keyword: "trades", Trades: true, Scope: ScopeTitle}},
+		{"trades tada68", Subscription{Keyword: "trades tada68", Scope: ScopeTitle}},
+		{"tada68 in:items trades", Subscription{Keyword: "tada68", Trades: true, Scope: ScopeItems}},
+		{"tada68 in:any", Subscription{Keyword: "tada68", Scope: ScopeAny}},
+		{"tada68 in:title in:any", Subscription{Keyword: "tada68 in:title", Scope: ScopeAny}},
+		{"tada68 in:body", Subscription{Keyword: "tada68 in:body", Scope: ScopeTitle}},
+		{"gmk minrep:10", Subscription{Keyword: "gmk", Scope: ScopeTitle, MinRep: 10}},
+		{"gmk minrep:10 in:items trades", Subscription{Keyword: "gmk", Trades: true, Scope: ScopeItems, MinRep: 10}},
+		{"gmk minrep:0", Subscription{Keyword: "gmk minrep:0", Scope: ScopeTitle}},
+		{"gmk minrep:ten", Subscription{Keyword: "gmk minrep:ten", Scope: ScopeTitle}},
+		{"tada68 once", Subscription{Keyword: "tada68", Scope: ScopeTitle, Once: true}},
+		{"tada68 for:14d once", Subscription{Keyword: "tada68", Scope: ScopeTitle, Once: true, For: "14d"}},
+		{"tada68 for:2w for:1d", Subscription{Keyword: "tada68 for:2w", Scope: ScopeTitle, For: "1d"}},
+		{"tada68 for:forever", Subscription{Keyword: "tada68 for:forever", Scope: ScopeTitle}},
+	}
+
+	for _, tt := range totalTests {
+		out := ParseSubscription(tt.in)
+
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Expected %+v, got %+v", tt.out, out)
+		}
+	}
+}
+
+func TestParseReputation(t *testing.T) {
+	totalTests := []struct {
+		in     string
+		trades int
+		ok     bool
+	}{
+		{"Trades: 42", 42, true},
+		{"trades:7", 7, true},
+		{"Vendor | Trades: 120", 120, true},
+		{"Trade: 1", 1, true},
+		{"Trades: none", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range totalTests {
+		trades, ok := ParseReputation(tt.in)
+
+		if trades != tt.trades || ok != tt.ok {
+			t.Errorf("Expected %q to be %d/%v, got %d/%v", tt.in, tt.trades, tt.ok, trades, ok)
+		}
+	}
+}
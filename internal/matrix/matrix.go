@@ -0,0 +1,275 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// syncTimeout is how long the homeserver holds a sync open waiting for events
+const syncTimeout = 30 * time.Second
+
+// Event is a text message sent to a room the bot is in
+type Event struct {
+	Room   string
+	Sender string
+	Body   string
+}
+
+// Channel is a channel of events from Matrix
+type Channel chan Event
+
+// Handler is a Matrix bot user, talking to a homeserver over the client-server API
+type Handler struct {
+	homeserver string
+	user       string
+	password   string
+	userID     string
+	token      string
+	since      string
+	txn        int64
+	client     *http.Client
+	channel    Channel
+	backoff    time.Duration
+	logger     *log.Logger
+}
+
+// Interface is the matrix public functions
+type Interface interface {
+	Start() (Channel, error)
+	SendMessage(string, string, string) error
+	IsRoomAdmin(string, string) (bool, error)
+}
+
+// syncResponse is the part of a sync the bot uses
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []struct {
+					Type    string `json:"type"`
+					Sender  string `json:"sender"`
+					Content struct {
+						MsgType string `json:"msgtype"`
+						Body    string `json:"body"`
+					} `json:"content"`
+				} `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+// powerLevels is the part of a room's m.room.power_levels state the bot uses
+type powerLevels struct {
+	Users        map[string]int `json:"users"`
+	UsersDefault int            `json:"users_default"`
+	StateDefault *int           `json:"state_default"`
+}
+
+// message is a formatted m.room.message
+type message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// Start logs in as the bot user and syncs with the homeserver in the background
+func (m *Handler) Start() (Channel, error) {
+	err := m.login()
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip whatever was said before the bot started
+	if err = m.sync(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			if err := m.sync(); err != nil {
+				m.logger.Printf("Unable to sync: %v", err)
+				time.Sleep(m.backoff)
+			}
+		}
+	}()
+	m.logger.Printf("Syncing as %s", m.userID)
+
+	return m.channel, nil
+}
+
+// login trades the bot user's password for an access token
+func (m *Handler) login() error {
+	var result struct {
+		AccessToken string `json:"access_token"`
+		UserID      string `json:"user_id"`
+	}
+	err := m.request(http.MethodPost, "/login", map[string]interface{}{
+		"type":       "m.login.password",
+		"identifier": map[string]string{"type": "m.id.user", "user": m.user},
+		"password":   m.password,
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("Unable to login: %v", err)
+	}
+
+	m.token = result.AccessToken
+	m.userID = result.UserID
+	return nil
+}
+
+// sync fetches new events once, joining rooms the bot is invited to and
+// queueing messages from others
+// Messages in the first sync are history and are skipped
+func (m *Handler) sync() error {
+	query := url.Values{"timeout": {fmt.Sprint(int(syncTimeout / time.Millisecond))}}
+	if m.since != "" {
+		query.Set("since", m.since)
+	}
+
+	var result syncResponse
+	err := m.request(http.MethodGet, "/sync?"+query.Encode(), nil, &result)
+	if err != nil {
+		return err
+	}
+
+	for room := range result.Rooms.Invite {
+		err = m.request(http.MethodPost, "/join/"+url.PathEscape(room), struct{}{}, nil)
+		if err != nil {
+			m.logger.Printf("Unable to join %s: %v", room, err)
+			continue
+		}
+		m.logger.Printf("Joined %s", room)
+	}
+
+	if m.since != "" {
+		for room, joined := range result.Rooms.Join {
+			for _, event := range joined.Timeline.Events {
+				if event.Type != "m.room.message" || event.Content.MsgType != "m.text" || event.Sender == m.userID {
+					continue
+				}
+				m.channel <- Event{Room: room, Sender: event.Sender, Body: event.Content.Body}
+			}
+		}
+	}
+
+	m.since = result.NextBatch
+	return nil
+}
+
+// SendMessage sends an HTML message to a room, with a plain text version for
+// clients that can't show HTML
+func (m *Handler) SendMessage(room, text, htmlBody string) error {
+	txn := atomic.AddInt64(&m.txn, 1)
+	path := fmt.Sprintf("/rooms/%s/send/m.room.message/%d-%d", url.PathEscape(room), time.Now().UnixNano(), txn)
+	return m.request(http.MethodPut, path, message{
+		MsgType:       "m.text",
+		Body:          text,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: htmlBody,
+	}, nil)
+}
+
+// IsRoomAdmin checks if a user's power level lets them change the room's settings
+func (m *Handler) IsRoomAdmin(room, user string) (bool, error) {
+	var levels powerLevels
+	err := m.request(http.MethodGet, fmt.Sprintf("/rooms/%s/state/m.room.power_levels", url.PathEscape(room)), nil, &levels)
+	if err != nil {
+		return false, err
+	}
+
+	// Rooms default to moderators changing their settings
+	required := 50
+	if levels.StateDefault != nil {
+		required = *levels.StateDefault
+	}
+	level, ok := levels.Users[user]
+	if !ok {
+		level = levels.UsersDefault
+	}
+	return level >= required, nil
+}
+
+// request calls the client-server API, decoding the JSON answer into result
+func (m *Handler) request(method, path string, body, result interface{}) error {
+	var contents []byte
+	if body != nil {
+		var err error
+		contents, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("Unable to encode request: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, m.homeserver+"/_matrix/client/v3"+path, bytes.NewReader(contents))
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return fmt.Errorf("Unable to send: %s: %s", resp.Status, failure.Error)
+		}
+		return fmt.Errorf("Unable to send: %s", resp.Status)
+	}
+
+	if result != nil {
+		err = json.NewDecoder(resp.Body).Decode(result)
+		if err != nil {
+			return fmt.Errorf("Unable to decode response: %v", err)
+		}
+	}
+	return nil
+}
+
+var linkRex = regexp.MustCompile(`(?is)<a href="([^"]*)">(.*?)</a>`)
+
+var tagRex = regexp.MustCompile(`(?s)<[^>]+>`)
+
+// Plain turns the bot's HTML messages into plain text, keeping link targets
+func Plain(htmlBody string) string {
+	text := linkRex.ReplaceAllString(htmlBody, "$2 ($1)")
+	return html.UnescapeString(tagRex.ReplaceAllString(text, ""))
+}
+
+// New creates a Matrix bot user given a homeserver URL and the bot's login
+func New(homeserver, user, password string) (*Handler, error) {
+	if homeserver == "" || user == "" || password == "" {
+		return nil, fmt.Errorf("Unable to setup: homeserver, user and password are required")
+	}
+
+	return &Handler{
+		homeserver: strings.TrimSuffix(homeserver, "/"),
+		user:       user,
+		password:   password,
+		client:     &http.Client{Timeout: syncTimeout + 10*time.Second},
+		channel:    make(Channel, 100),
+		backoff:    5 * time.Second,
+		logger:     log.New(os.Stderr, "[MATRIX] ", log.LstdFlags),
+	}, nil
+}
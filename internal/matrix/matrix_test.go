@@ -0,0 +1,212 @@
+package matrix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// homeserver is a local fake of the client-server API
+type homeserver struct {
+	requests []string
+	syncs    []string
+}
+
+func (h *homeserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3")
+
+	switch {
+	case path == "/login":
+		h.requests = append(h.requests, fmt.Sprintf("login %s", body))
+		if !strings.Contains(string(body), `"password":"hunter2"`) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errcode":"M_FORBIDDEN","error":"Invalid password"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"abc","user_id":"@mechkeybot:example.com"}`)
+
+	case r.Header.Get("Authorization") != "Bearer abc":
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"errcode":"M_UNKNOWN_TOKEN","error":"Unknown token"}`)
+
+	case path == "/sync":
+		h.requests = append(h.requests, fmt.Sprintf("sync since=%s", r.URL.Query().Get("since")))
+		next := h.syncs[0]
+		h.syncs = h.syncs[1:]
+		fmt.Fprint(w, next)
+
+	case path == "/rooms/!room:example.com/state/m.room.power_levels":
+		fmt.Fprint(w, `{"users":{"@alice:example.com":100,"@bob:example.com":50},"users_default":0,"state_default":100}`)
+
+	case strings.HasSuffix(path, "/state/m.room.power_levels"):
+		fmt.Fprint(w, `{"users":{"@alice:example.com":100,"@bob:example.com":50},"users_default":0}`)
+
+	default:
+		h.requests = append(h.requests, fmt.Sprintf("%s %s %s", r.Method, path, body))
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+func testHandler(server *httptest.Server, password string) *Handler {
+	obj, _ := New(server.URL+"/", "mechkeybot", password)
+	obj.logger = log.New(ioutil.Discard, "", 0)
+	return obj
+}
+
+func syncBatch(next, room, sender, body string) string {
+	message := map[string]interface{}{
+		"next_batch": next,
+		"rooms": map[string]interface{}{
+			"join": map[string]interface{}{
+				room: map[string]interface{}{
+					"timeline": map[string]interface{}{
+						"events": []interface{}{
+							map[string]interface{}{
+								"type":    "m.room.message",
+								"sender":  sender,
+								"content": map[string]string{"msgtype": "m.text", "body": body},
+							},
+							map[string]interface{}{
+								"type":    "m.room.member",
+								"sender":  sender,
+								"content": map[string]string{"membership": "join"},
+							},
+						},
+					},
+				},
+			},
+			"invite": map[string]interface{}{
+				"!new:example.com": map[string]interface{}{},
+			},
+		},
+	}
+	contents, _ := json.Marshal(message)
+	return string(contents)
+}
+
+func TestSync(t *testing.T) {
+	fake := &homeserver{syncs: []string{
+		syncBatch("s1", "!old:example.com", "@alice:example.com", "/selling old"),
+		syncBatch("s2", "!room:example.com", "@alice:example.com", "/selling tada68"),
+		syncBatch("s3", "!room:example.com", "@mechkeybot:example.com", "Okay"),
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	obj := testHandler(server, "hunter2")
+
+	err := obj.login()
+	if err != nil {
+		t.Fatalf("Expected no error, got %+v", err)
+	}
+	for i := 0; i < 3; i++ {
+		err = obj.sync()
+		if err != nil {
+			t.Errorf("Expected no error, got %+v", err)
+		}
+	}
+
+	expected := []string{
+		`login {"identifier":{"type":"m.id.user","user":"mechkeybot"},"password":"hunter2","type":"m.login.password"}`,
+		"sync since=",
+		"POST /join/!new:example.com {}",
+		"sync since=s1",
+		"POST /join/!new:example.com {}",
+		"sync since=s2",
+		"POST /join/!new:example.com {}",
+	}
+	if !reflect.DeepEqual(fake.requests, expected) {
+		t.Errorf("Expected %q to equal %q", fake.requests, expected)
+	}
+
+	// Only the second batch has a new message from someone else
+	if len(obj.channel) != 1 {
+		t.Fatalf("Expected one event, got %d", len(obj.channel))
+	}
+	expectedEvent := Event{Room: "!room:example.com", Sender: "@alice:example.com", Body: "/selling tada68"}
+	if actual := <-obj.channel; !reflect.DeepEqual(actual, expectedEvent) {
+		t.Errorf("Expected %+v to equal %+v", actual, expectedEvent)
+	}
+}
+
+func TestLoginFailed(t *testing.T) {
+	server := httptest.NewServer(&homeserver{})
+	defer server.Close()
+
+	_, err := testHandler(server, "wrong").Start()
+	if err == nil || err.Error() != "Unable to login: Unable to send: 403 Forbidden: Invalid password" {
+		t.Errorf("Expected a login error, got %+v", err)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	fake := &homeserver{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	obj := testHandler(server, "hunter2")
+	obj.token = "abc"
+
+	err := obj.SendMessage("!room:example.com", "Tada68", "<b>Tada68</b>")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if len(fake.requests) != 1 || !strings.HasPrefix(fake.requests[0], "PUT /rooms/!room:example.com/send/m.room.message/") {
+		t.Fatalf("Expected a message to be sent, got %q", fake.requests)
+	}
+	expected := `{"msgtype":"m.text","body":"Tada68","format":"org.matrix.custom.html","formatted_body":"\u003cb\u003eTada68\u003c/b\u003e"}`
+	if !strings.HasSuffix(fake.requests[0], " "+expected) {
+		t.Errorf("Expected %q to end with %q", fake.requests[0], expected)
+	}
+
+	obj.token = "expired"
+	err = obj.SendMessage("!room:example.com", "Tada68", "<b>Tada68</b>")
+	if err == nil || err.Error() != "Unable to send: 401 Unauthorized: Unknown token" {
+		t.Errorf("Expected an unauthorized error, got %+v", err)
+	}
+}
+
+func TestIsRoomAdmin(t *testing.T) {
+	server := httptest.NewServer(&homeserver{})
+	defer server.Close()
+	obj := testHandler(server, "hunter2")
+	obj.token = "abc"
+
+	for _, test := range []struct {
+		room  string
+		user  string
+		admin bool
+	}{
+		{"!room:example.com", "@alice:example.com", true},
+		{"!room:example.com", "@bob:example.com", false},
+		{"!other:example.com", "@bob:example.com", true},
+		{"!other:example.com", "@carol:example.com", false},
+	} {
+		admin, err := obj.IsRoomAdmin(test.room, test.user)
+		if err != nil {
+			t.Errorf("Expected no error, got %+v", err)
+		}
+		if admin != test.admin {
+			t.Errorf("Expected %s in %s admin to be %t", test.user, test.room, test.admin)
+		}
+	}
+
+	obj.token = "expired"
+	if admin, err := obj.IsRoomAdmin("!room:example.com", "@alice:example.com"); err == nil || admin {
+		t.Errorf("Expected an error, got %t", admin)
+	}
+}
+
+func TestPlain(t *testing.T) {
+	actual := Plain(`<b>Tada68</b> &amp; more [<a href="https://example.com">web</a>] <i>(matched)</i>`)
+	expected := "Tada68 & more [web (https://example.com)] (matched)"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
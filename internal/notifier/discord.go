@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// discordHosts are the hosts that serve Discord webhooks
+var discordHosts = map[string]bool{
+	"discord.com":        true,
+	"discordapp.com":     true,
+	"ptb.discord.com":    true,
+	"canary.discord.com": true,
+}
+
+// markdownEscaper escapes the characters Discord treats as formatting
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`",
+	`|`, `\|`, `>`, `\>`, `[`, `\[`, `]`, `\]`,
+)
+
+// discordMessage is the body of a webhook request
+type discordMessage struct {
+	Content         string                 `json:"content"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+// discordAllowedMentions stops titles from pinging anyone
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// DiscordHandler delivers notifications to Discord webhooks
+type DiscordHandler struct {
+	client *http.Client
+}
+
+// NewDiscord creates a notifier for Discord webhooks
+func NewDiscord() *DiscordHandler {
+	return &DiscordHandler{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Validate checks the target is a Discord webhook URL
+func (d *DiscordHandler) Validate(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || !discordHosts[u.Host] || !strings.HasPrefix(u.Path, "/api/webhooks/") {
+		return fmt.Errorf("not a Discord webhook URL")
+	}
+	return nil
+}
+
+// Notify posts the notification to a webhook URL
+func (d *DiscordHandler) Notify(target string, n Notification) error {
+	body, err := json.Marshal(discordMessage{
+		Content:         RenderMarkdown(n),
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to encode message: %v", err)
+	}
+
+	resp, err := d.client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unable to send: %s", resp.Status)
+	}
+	return nil
+}
+
+// RenderMarkdown formats a notification using Discord's markdown
+func RenderMarkdown(n Notification) string {
+	author := ""
+	if n.Author != "" {
+		author = fmt.Sprintf(" by /u/%s", markdownEscaper.Replace(n.Author))
+		if n.HasTrades {
+			author = fmt.Sprintf("%s (%d trades)", author, n.Trades)
+		}
+	}
+
+	title := highlight(n, markdownEscaper.Replace, "**", "**")
+	return fmt.Sprintf("%s%s [web](<%s>) [%s](<%s>) *(%s)*", title, author, n.URL, n.AppLabel(), n.AppURL(), markdownEscaper.Replace(n.Reason))
+}
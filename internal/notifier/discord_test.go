@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDiscordNotify(t *testing.T) {
+	var actual []discordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON post, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		var msg discordMessage
+		json.Unmarshal(body, &msg)
+		actual = append(actual, msg)
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	obj := NewDiscord()
+	n := Notification{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Highlight: regexp.MustCompile("(?i)(tada68)"),
+		Reason:    "matched selling tada68",
+	}
+
+	err := obj.Notify(server.URL+"/api/webhooks/1/abc", n)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	err = obj.Notify(server.URL+"/missing", n)
+	if err == nil || err.Error() != "Unable to send: 404 Not Found" {
+		t.Errorf("Expected a not found error, got %+v", err)
+	}
+
+	content := `\[US-CA\] \[H\] **Tada68** \[W\] PayPal [web](<https://example.com/post>) [app](<https://git.io/vhZZN#/r/mechmarket/abc>) *(matched selling tada68)*`
+	expected := []discordMessage{
+		{Content: content, AllowedMentions: discordAllowedMentions{Parse: []string{}}},
+		{Content: content, AllowedMentions: discordAllowedMentions{Parse: []string{}}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	actual := RenderMarkdown(Notification{
+		Title:     "[US-NY] [H] *GMK* _Laser_ [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "key_fan",
+		Trades:    3,
+		HasTrades: true,
+		Highlight: regexp.MustCompile(`(?i)(\[[^\]]+\])`),
+		Reason:    "followed /u/key_fan",
+	})
+
+	expected := `**\[US-NY\]** **\[H\]** \*GMK\* \_Laser\_ **\[W\]** PayPal by /u/key\_fan (3 trades) [web](<https://example.com/post>) [app](<https://git.io/vhZZN#/r/mechmarket/abc>) *(followed /u/key\_fan)*`
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestDiscordValidate(t *testing.T) {
+	obj := NewDiscord()
+
+	for target, valid := range map[string]bool{
+		"https://discord.com/api/webhooks/123/abc":    true,
+		"https://discordapp.com/api/webhooks/123/abc": true,
+		"http://discord.com/api/webhooks/123/abc":     false,
+		"https://example.com/api/webhooks/123/abc":    false,
+		"https://discord.com/channels/123":            false,
+		"not a url":                                   false,
+	} {
+		err := obj.Validate(target)
+		if valid && err != nil {
+			t.Errorf("Expected %s to be valid, got %+v", target, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("Expected %s to be invalid", target)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"fmt"
+	"html"
+	"net/mail"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/email"
+)
+
+var emailTemplate = `<!DOCTYPE html>
+<html><body>
+<p style="white-space: pre-line">%s</p>
+<p style="font-size: small; color: #888"><a href="%s">Unsubscribe</a> from these matches</p>
+</body></html>`
+
+var verifyTemplate = `<!DOCTYPE html>
+<html><body>
+<p>Your code is <b>%s</b>, send <code>/notify email verify %s</code> to the bot to start getting your matches here.</p>
+<p style="font-size: small; color: #888">If you didn't ask for this, you can ignore this email.</p>
+</body></html>`
+
+// EmailHandler delivers notifications as email
+type EmailHandler struct {
+	app email.Interface
+}
+
+// NewEmail creates a notifier sending through the bot's SMTP server
+func NewEmail(app email.Interface) *EmailHandler {
+	return &EmailHandler{app: app}
+}
+
+// Validate checks the target is a bare email address
+func (e *EmailHandler) Validate(target string) error {
+	addr, err := mail.ParseAddress(target)
+	if err != nil || addr.Address != target {
+		return fmt.Errorf("not an email address (e.g. you@example.com)")
+	}
+	return nil
+}
+
+// Verify emails a confirmation code, proving the address belongs to the user
+func (e *EmailHandler) Verify(target, code string) error {
+	text := fmt.Sprintf("Your code is %s, send \"/notify email verify %s\" to the bot to start getting your matches here.\n\nIf you didn't ask for this, you can ignore this email.", code, code)
+	return e.app.Send(target, "Confirm your email address", text, fmt.Sprintf(verifyTemplate, code, code))
+}
+
+// Notify emails the notification with a link to unsubscribe
+func (e *EmailHandler) Notify(target string, n Notification) error {
+	unsubscribe := e.app.UnsubscribeURL(target)
+	text := fmt.Sprintf("%s\n\nUnsubscribe: %s", RenderText(n), unsubscribe)
+	return e.app.Send(target, n.Title, text, fmt.Sprintf(emailTemplate, RenderHTML(n), html.EscapeString(unsubscribe)))
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/email"
+)
+
+// fakeEmail records the email sent through it
+type fakeEmail struct {
+	sent []string
+}
+
+func (f *fakeEmail) Start() (email.Channel, error) {
+	return nil, nil
+}
+
+func (f *fakeEmail) Send(to, subject, text, html string) error {
+	f.sent = append(f.sent, fmt.Sprintf("%s|%s|%s|%s", to, subject, text, html))
+	return nil
+}
+
+func (f *fakeEmail) UnsubscribeURL(to string) string {
+	return "https://bot.example.com/email/unsubscribe?address=" + to + "&token=abc"
+}
+
+func TestEmailNotify(t *testing.T) {
+	app := &fakeEmail{}
+	obj := NewEmail(app)
+
+	err := obj.Notify("you@example.com", Notification{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob",
+		Highlight: regexp.MustCompile("(?i)(tada68)"),
+		Reason:    "matched selling tada68",
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	text := "[US-CA] [H] Tada68 [W] PayPal by /u/bob\nweb: https://example.com/post\napp: https://git.io/vhZZN#/r/mechmarket/abc\n(matched selling tada68)\n\nUnsubscribe: https://bot.example.com/email/unsubscribe?address=you@example.com&token=abc"
+	html := fmt.Sprintf(emailTemplate, `[US-CA] [H] <b>Tada68</b> [W] PayPal by /u/bob [<a href="https://example.com/post">web</a>] [<a href="https://git.io/vhZZN#/r/mechmarket/abc">app</a>] <i>(matched selling tada68)</i>`, "https://bot.example.com/email/unsubscribe?address=you@example.com&amp;token=abc")
+	expected := []string{
+		"you@example.com|[US-CA] [H] Tada68 [W] PayPal|" + text + "|" + html,
+	}
+	if !reflect.DeepEqual(app.sent, expected) {
+		t.Errorf("Expected %q to equal %q", app.sent, expected)
+	}
+}
+
+func TestEmailVerify(t *testing.T) {
+	app := &fakeEmail{}
+	obj := NewEmail(app)
+
+	err := obj.Verify("you@example.com", "123456")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if len(app.sent) != 1 || !regexp.MustCompile(`^you@example.com\|Confirm your email address\|Your code is 123456, `).MatchString(app.sent[0]) {
+		t.Errorf("Expected a confirmation code, got %q", app.sent)
+	}
+}
+
+func TestEmailValidate(t *testing.T) {
+	obj := NewEmail(&fakeEmail{})
+	for target, valid := range map[string]bool{
+		"you@example.com":         true,
+		"you+mech@example.co.uk":  true,
+		"You <you@example.com>":   false,
+		"you@example.com\nBcc: x": false,
+		"example.com":             false,
+	} {
+		err := obj.Validate(target)
+		if (err == nil) != valid {
+			t.Errorf("Expected %q valid to be %t, got %+v", target, valid, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package notifier
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+)
+
+// matrixRoomRex matches Matrix room IDs
+var matrixRoomRex = regexp.MustCompile(`^![^:\s]+:\S+$`)
+
+// MatrixHandler delivers notifications as HTML messages in a Matrix room
+type MatrixHandler struct {
+	app matrix.Interface
+}
+
+// NewMatrix creates a notifier sharing the bot's Matrix user
+func NewMatrix(app matrix.Interface) *MatrixHandler {
+	return &MatrixHandler{app: app}
+}
+
+// Validate checks the target is a room ID
+func (m *MatrixHandler) Validate(target string) error {
+	if !matrixRoomRex.MatchString(target) {
+		return fmt.Errorf("not a Matrix room ID (e.g. !abc123:matrix.org)")
+	}
+	return nil
+}
+
+// Notify sends the notification to a room the bot has joined
+func (m *MatrixHandler) Notify(target string, n Notification) error {
+	html := RenderHTML(n)
+	return m.app.SendMessage(target, matrix.Plain(html), html)
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+)
+
+// fakeMatrix records the messages sent through it
+type fakeMatrix struct {
+	sent []string
+}
+
+func (f *fakeMatrix) Start() (matrix.Channel, error) {
+	return nil, nil
+}
+
+func (f *fakeMatrix) SendMessage(room, text, html string) error {
+	f.sent = append(f.sent, fmt.Sprintf("%s|%s|%s", room, text, html))
+	return nil
+}
+
+func (f *fakeMatrix) IsRoomAdmin(room, user string) (bool, error) {
+	return false, nil
+}
+
+func TestMatrixNotify(t *testing.T) {
+	app := &fakeMatrix{}
+	obj := NewMatrix(app)
+
+	err := obj.Notify("!room:example.com", Notification{
+		Title:     "Tada68",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Reason:    "matched selling tada68",
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	expected := []string{
+		`!room:example.com|Tada68 [web (https://example.com/post)] [app (https://git.io/vhZZN#/r/mechmarket/abc)] (matched selling tada68)|Tada68 [<a href="https://example.com/post">web</a>] [<a href="https://git.io/vhZZN#/r/mechmarket/abc">app</a>] <i>(matched selling tada68)</i>`,
+	}
+	if !reflect.DeepEqual(app.sent, expected) {
+		t.Errorf("Expected %q to equal %q", app.sent, expected)
+	}
+}
+
+func TestMatrixValidate(t *testing.T) {
+	obj := NewMatrix(&fakeMatrix{})
+	for target, valid := range map[string]bool{
+		"!abc123:matrix.org":     true,
+		"!abc:example.com:80":    true,
+		"#mechmarket:matrix.org": false,
+		"@alice:matrix.org":      false,
+		"!abc":                   false,
+	} {
+		err := obj.Validate(target)
+		if (err == nil) != valid {
+			t.Errorf("Expected %s valid to be %t, got %+v", target, valid, err)
+		}
+	}
+}
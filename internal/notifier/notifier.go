@@ -0,0 +1,187 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Transports that notifications can be delivered over
+const (
+	Telegram = "telegram"
+	Discord  = "discord"
+	Slack    = "slack"
+	Email    = "email"
+	Webhook  = "webhook"
+	Matrix   = "matrix"
+	Ntfy     = "ntfy"
+	Gotify   = "gotify"
+)
+
+// Address is where a notification is delivered
+type Address struct {
+	// Transport is the name of the notifier that delivers it
+	Transport string
+	// Target is the transport-specific recipient, like a chat ID or webhook URL
+	Target string
+}
+
+// Notification is a post worth telling someone about, rendered by each transport
+type Notification struct {
+	Title     string
+	URL       string
+	Permalink string
+	Author    string
+	Trades    int
+	HasTrades bool
+	// Highlight matches the parts of the title to emphasize
+	Highlight *regexp.Regexp
+	// Reason explains why the post was sent
+	Reason string
+	// Type is the kind of post that matched, like selling
+	Type string
+	// Keywords are the watched keywords that matched, empty for followed authors
+	Keywords []string
+	// Region, Have and Want are parsed from the title of sales posts
+	Region string
+	Have   string
+	Want   string
+	// Price, Excerpt and Image are from the body of the post
+	Price   string
+	Excerpt string
+	Image   string
+	// UserID is who the notification is for
+	UserID int64
+	// Style and App are how the user likes notifications rendered
+	Style string
+	App   string
+	// Rich adds the excerpt to compact notifications, and the image where it can be shown
+	Rich bool
+}
+
+// Interface is the notifier public functions
+type Interface interface {
+	Validate(string) error
+	Notify(string, Notification) error
+}
+
+// Verifier is implemented by transports that confirm a target belongs to the
+// user, by sending it a code, before anything else is sent to it
+type Verifier interface {
+	Verify(string, string) error
+}
+
+// Signer is implemented by transports that sign what they send, with a key
+// the user needs to check it
+type Signer interface {
+	SigningKey(string) string
+}
+
+// String describes an address for logs and replies
+func (a Address) String() string {
+	return fmt.Sprintf("%s:%s", a.Transport, a.Target)
+}
+
+// highlight escapes the title and wraps the highlighted parts in the given markup
+func highlight(n Notification, escape func(string) string, open, close string) string {
+	if n.Highlight == nil {
+		return escape(n.Title)
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, loc := range n.Highlight.FindAllStringIndex(n.Title, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		out.WriteString(escape(n.Title[last:loc[0]]))
+		out.WriteString(open + escape(n.Title[loc[0]:loc[1]]) + close)
+		last = loc[1]
+	}
+	out.WriteString(escape(n.Title[last:]))
+
+	return out.String()
+}
+
+// parsePublicURL checks a target is an https URL that isn't on the bot's own machine
+func parsePublicURL(target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, fmt.Errorf("not an https URL")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	ip := net.ParseIP(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && !publicIP(ip)) {
+		return nil, fmt.Errorf("not a public URL")
+	}
+	return u, nil
+}
+
+// privateNets are the address ranges of private and carrier-grade NAT networks
+var privateNets = parseNets("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+// parseNets parses a list of CIDR ranges
+func parseNets(cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// publicIP checks an address can be reached from the internet
+// net.IP.IsPrivate needs a newer Go than the bot is built with, so the ranges are listed
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialPublic connects to the first public address of a host
+// Hostnames are resolved and checked here so they can't point at the bot's own network
+func dialPublic(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	for _, ip := range ips {
+		if publicIP(ip.IP) {
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+		}
+	}
+	return nil, fmt.Errorf("not a public address: %s", host)
+}
+
+// publicClient returns an HTTP client for user supplied URLs, which only connects to public addresses
+// Redirects go through the same check
+func publicClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialPublic,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/matcher"
+)
+
+// ntfyTopicRex matches the path of an ntfy topic URL
+var ntfyTopicRex = regexp.MustCompile(`^/[-_A-Za-z0-9]{1,64}$`)
+
+// pushStyle is how urgent a type of post is and the tags it's shown with
+type pushStyle struct {
+	// Priority is on ntfy's scale of 1 (min) to 5 (max)
+	Priority int
+	// Tags are ntfy emoji short codes, followed by the type itself
+	Tags []string
+}
+
+// pushStyles are the styles of each type of post
+// Giveaways go fast, while vendors and group buys can wait
+var pushStyles = map[string]pushStyle{
+	matcher.Selling:       {Priority: 3, Tags: []string{"moneybag"}},
+	matcher.Buying:        {Priority: 3, Tags: []string{"shopping_cart"}},
+	matcher.Trading:       {Priority: 3, Tags: []string{"arrows_counterclockwise"}},
+	matcher.Vendor:        {Priority: 2, Tags: []string{"convenience_store"}},
+	matcher.Artisan:       {Priority: 2, Tags: []string{"art"}},
+	matcher.GroupBuy:      {Priority: 2, Tags: []string{"package"}},
+	matcher.InterestCheck: {Priority: 2, Tags: []string{"thinking"}},
+	matcher.Giveaway:      {Priority: 5, Tags: []string{"gift"}},
+}
+
+// style returns the priority and tags of a notification
+func style(n Notification) pushStyle {
+	s, ok := pushStyles[n.Type]
+	if !ok {
+		s = pushStyle{Priority: 3, Tags: []string{"keyboard"}}
+	}
+
+	tags := append([]string{}, s.Tags...)
+	if n.Type != "" {
+		tags = append(tags, n.Type)
+	}
+	if len(n.Keywords) == 0 && n.Author != "" {
+		tags = append(tags, "followed")
+	}
+	return pushStyle{Priority: s.Priority, Tags: tags}
+}
+
+// ntfyMessage is a message published to ntfy as JSON
+type ntfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority"`
+	Tags     []string     `json:"tags"`
+	Click    string       `json:"click"`
+	Actions  []ntfyAction `json:"actions"`
+}
+
+// ntfyAction is a button on an ntfy notification
+type ntfyAction struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// gotifyMessage is a message sent to Gotify
+type gotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras"`
+}
+
+// pushMessage describes a notification in the body of a push notification
+func pushMessage(n Notification) string {
+	if n.Author == "" {
+		return n.Reason
+	}
+
+	author := fmt.Sprintf("/u/%s", n.Author)
+	if n.HasTrades {
+		author = fmt.Sprintf("%s (%d trades)", author, n.Trades)
+	}
+	return fmt.Sprintf("%s - %s", author, n.Reason)
+}
+
+// postJSON sends a JSON body, treating anything but a 2xx as an error
+func postJSON(client *http.Client, target string, body interface{}) error {
+	contents, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("Unable to encode message: %v", err)
+	}
+
+	resp, err := client.Post(target, "application/json", bytes.NewReader(contents))
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unable to send: %s", resp.Status)
+	}
+	return nil
+}
+
+// NtfyHandler publishes notifications to an ntfy topic
+type NtfyHandler struct {
+	client *http.Client
+}
+
+// NewNtfy creates a notifier publishing to ntfy servers
+func NewNtfy() *NtfyHandler {
+	return &NtfyHandler{client: publicClient()}
+}
+
+// Validate checks the target is the URL of an ntfy topic
+func (h *NtfyHandler) Validate(target string) error {
+	u, err := parsePublicURL(target)
+	if err != nil {
+		return err
+	}
+	if !ntfyTopicRex.MatchString(u.Path) || u.RawQuery != "" {
+		return fmt.Errorf("not an ntfy topic URL (e.g. https://ntfy.sh/mytopic)")
+	}
+	return nil
+}
+
+// Notify publishes the notification to the topic
+// Topics are published to as JSON on the server's root so titles can be unicode
+func (h *NtfyHandler) Notify(target string, n Notification) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("not an ntfy topic URL: %v", err)
+	}
+	topic := strings.TrimPrefix(u.Path, "/")
+	u.Path = "/"
+
+	s := style(n)
+	return postJSON(h.client, u.String(), ntfyMessage{
+		Topic:    topic,
+		Title:    n.Title,
+		Message:  pushMessage(n),
+		Priority: s.Priority,
+		Tags:     s.Tags,
+		Click:    n.URL,
+		Actions: []ntfyAction{
+			{Action: "view", Label: strings.Title(n.AppLabel()), URL: n.AppURL()},
+		},
+	})
+}
+
+// GotifyHandler sends notifications to a Gotify application
+type GotifyHandler struct {
+	client *http.Client
+}
+
+// NewGotify creates a notifier sending to Gotify servers
+func NewGotify() *GotifyHandler {
+	return &GotifyHandler{client: publicClient()}
+}
+
+// Validate checks the target is a Gotify server URL with an application token
+func (h *GotifyHandler) Validate(target string) error {
+	u, err := parsePublicURL(target)
+	if err != nil {
+		return err
+	}
+	if u.Query().Get("token") == "" {
+		return fmt.Errorf("not a Gotify URL with a token (e.g. https://push.example.com/?token=AbCdEf)")
+	}
+	return nil
+}
+
+// Notify sends the notification to the application
+// Gotify priorities go up to 10, so ntfy's are doubled
+func (h *GotifyHandler) Notify(target string, n Notification) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("not a Gotify URL: %v", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/message"
+
+	s := style(n)
+	return postJSON(h.client, u.String(), gotifyMessage{
+		Title:    n.Title,
+		Message:  fmt.Sprintf("%s\n\n[web](%s) [%s](%s)", pushMessage(n), n.URL, n.AppLabel(), n.AppURL()),
+		Priority: s.Priority * 2,
+		Extras: map[string]interface{}{
+			"client::display":      map[string]string{"contentType": "text/markdown"},
+			"client::notification": map[string]interface{}{"click": map[string]string{"url": n.URL}},
+		},
+	})
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// pushServer records the path and JSON body of each request
+func pushServer(actual *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*actual = append(*actual, r.URL.RequestURI()+" "+string(body))
+		if r.URL.Query().Get("token") == "bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func pushNotification() Notification {
+	return Notification{
+		Title:     "[GA] Free Tada68",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob",
+		Trades:    3,
+		HasTrades: true,
+		Reason:    "matched giveaway tada68",
+		Type:      "giveaway",
+		Keywords:  []string{"tada68"},
+	}
+}
+
+func TestNtfyNotify(t *testing.T) {
+	var actual []string
+	server := pushServer(&actual)
+	defer server.Close()
+
+	obj := NewNtfy()
+	obj.client = server.Client()
+	err := obj.Notify(server.URL+"/mechmarket", pushNotification())
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+
+	expected := ntfyMessage{
+		Topic:    "mechmarket",
+		Title:    "[GA] Free Tada68",
+		Message:  "/u/bob (3 trades) - matched giveaway tada68",
+		Priority: 5,
+		Tags:     []string{"gift", "giveaway"},
+		Click:    "https://example.com/post",
+		Actions:  []ntfyAction{{Action: "view", Label: "App", URL: "https://git.io/vhZZN#/r/mechmarket/abc"}},
+	}
+	contents, _ := json.Marshal(expected)
+	if !reflect.DeepEqual(actual, []string{"/ " + string(contents)}) {
+		t.Errorf("Expected %q to equal %q", actual, []string{"/ " + string(contents)})
+	}
+}
+
+func TestGotifyNotify(t *testing.T) {
+	var actual []string
+	server := pushServer(&actual)
+	defer server.Close()
+
+	n := pushNotification()
+	n.Type = "vendor"
+	obj := NewGotify()
+	obj.client = server.Client()
+	err := obj.Notify(server.URL+"/?token=abc", n)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	err = obj.Notify(server.URL+"/gotify?token=bad", n)
+	if err == nil || err.Error() != "Unable to send: 401 Unauthorized" {
+		t.Errorf("Expected an unauthorized error, got %+v", err)
+	}
+
+	if len(actual) != 2 || actual[1][:len("/gotify/message?token=bad ")] != "/gotify/message?token=bad " {
+		t.Fatalf("Expected two messages, got %q", actual)
+	}
+	var msg gotifyMessage
+	json.Unmarshal([]byte(actual[0][len("/message?token=abc "):]), &msg)
+	expected := gotifyMessage{
+		Title:    "[GA] Free Tada68",
+		Message:  "/u/bob (3 trades) - matched giveaway tada68\n\n[web](https://example.com/post) [app](https://git.io/vhZZN#/r/mechmarket/abc)",
+		Priority: 4,
+		Extras: map[string]interface{}{
+			"client::display":      map[string]interface{}{"contentType": "text/markdown"},
+			"client::notification": map[string]interface{}{"click": map[string]interface{}{"url": "https://example.com/post"}},
+		},
+	}
+	if !reflect.DeepEqual(msg, expected) {
+		t.Errorf("Expected %+v to equal %+v", msg, expected)
+	}
+}
+
+func TestPushStyle(t *testing.T) {
+	for _, test := range []struct {
+		n        Notification
+		expected pushStyle
+	}{
+		{Notification{Type: "giveaway", Keywords: []string{"*"}}, pushStyle{Priority: 5, Tags: []string{"gift", "giveaway"}}},
+		{Notification{Type: "groupbuy", Keywords: []string{"gmk"}}, pushStyle{Priority: 2, Tags: []string{"package", "groupbuy"}}},
+		{Notification{Type: "selling", Author: "bob"}, pushStyle{Priority: 3, Tags: []string{"moneybag", "selling", "followed"}}},
+		{Notification{}, pushStyle{Priority: 3, Tags: []string{"keyboard"}}},
+	} {
+		if actual := style(test.n); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, test.expected)
+		}
+	}
+}
+
+func TestPushValidate(t *testing.T) {
+	for _, test := range []struct {
+		n      Interface
+		target string
+		valid  bool
+	}{
+		{NewNtfy(), "https://ntfy.sh/mechmarket_alerts", true},
+		{NewNtfy(), "https://ntfy.sh/", false},
+		{NewNtfy(), "https://ntfy.sh/a/b", false},
+		{NewNtfy(), "http://ntfy.sh/mechmarket", false},
+		{NewGotify(), "https://push.example.com/?token=abc", true},
+		{NewGotify(), "https://push.example.com/gotify?token=abc", true},
+		{NewGotify(), "https://push.example.com/", false},
+		{NewGotify(), "https://localhost/?token=abc", false},
+	} {
+		err := test.n.Validate(test.target)
+		if (err == nil) != test.valid {
+			t.Errorf("Expected %s valid to be %t, got %+v", test.target, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+)
+
+// slackTargetRex matches Slack public and private channel IDs
+// User and direct message IDs aren't allowed, so matches can't be sent to someone else's DMs
+var slackTargetRex = regexp.MustCompile(`^[CG][A-Z0-9]{6,}$`)
+
+// SlackHandler delivers notifications as Slack Block Kit messages
+type SlackHandler struct {
+	app slack.Interface
+}
+
+// NewSlack creates a notifier sharing the bot's Slack app
+func NewSlack(app slack.Interface) *SlackHandler {
+	return &SlackHandler{app: app}
+}
+
+// Validate checks the target is a Slack channel ID
+func (s *SlackHandler) Validate(target string) error {
+	if !slackTargetRex.MatchString(target) {
+		return fmt.Errorf("not a Slack channel ID (e.g. C0123ABCD)")
+	}
+	return nil
+}
+
+// Notify posts the notification to a channel or user
+func (s *SlackHandler) Notify(target string, n Notification) error {
+	return s.app.PostMessage(target, RenderMrkdwn(n), RenderBlocks(n))
+}
+
+// RenderMrkdwn formats a notification as a single line of Slack mrkdwn
+// It's shown in notifications and by clients that can't show blocks
+func RenderMrkdwn(n Notification) string {
+	return fmt.Sprintf("%s%s <%s|web> <%s|%s> _(%s)_", highlight(n, slack.Escape, "*", "*"), slackAuthor(n), n.URL, n.AppURL(), n.AppLabel(), slack.Escape(n.Reason))
+}
+
+// RenderBlocks formats a notification as Block Kit blocks
+func RenderBlocks(n Notification) []slack.Block {
+	buttons := []slack.Element{slack.LinkButton("web", "Web", n.URL)}
+	if n.HasWebApp() {
+		buttons = append(buttons, slack.LinkButton("app", strings.Title(n.AppLabel()), n.AppURL()))
+	}
+	return []slack.Block{
+		slack.Section(fmt.Sprintf("%s%s\n_(%s)_", highlight(n, slack.Escape, "*", "*"), slackAuthor(n), slack.Escape(n.Reason))),
+		slack.Actions(buttons...),
+	}
+}
+
+// slackAuthor describes the author of the post
+func slackAuthor(n Notification) string {
+	if n.Author == "" {
+		return ""
+	}
+	if n.HasTrades {
+		return fmt.Sprintf(" by /u/%s (%d trades)", slack.Escape(n.Author), n.Trades)
+	}
+	return fmt.Sprintf(" by /u/%s", slack.Escape(n.Author))
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/slack"
+)
+
+// fakeSlack records the messages posted to it
+type fakeSlack struct {
+	posted []string
+}
+
+func (f *fakeSlack) Start() (slack.Channel, error) {
+	return nil, nil
+}
+
+func (f *fakeSlack) PostMessage(channel, text string, blocks []slack.Block) error {
+	f.posted = append(f.posted, fmt.Sprintf("%s/%s/%d", channel, text, len(blocks)))
+	return nil
+}
+
+func (f *fakeSlack) Respond(string, string, []slack.Block, bool) error {
+	return nil
+}
+
+func TestSlackNotify(t *testing.T) {
+	app := &fakeSlack{}
+	obj := NewSlack(app)
+
+	err := obj.Notify("C0123ABCD", Notification{
+		Title:     "[US-CA] [H] Tada68 <new> [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob",
+		Trades:    3,
+		HasTrades: true,
+		Highlight: regexp.MustCompile("(?i)(tada68)"),
+		Reason:    "matched selling tada68",
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	expected := []string{
+		"C0123ABCD/[US-CA] [H] *Tada68* &lt;new&gt; [W] PayPal by /u/bob (3 trades) <https://example.com/post|web> <https://git.io/vhZZN#/r/mechmarket/abc|app> _(matched selling tada68)_/2",
+	}
+	if !reflect.DeepEqual(app.posted, expected) {
+		t.Errorf("Expected %q to equal %q", app.posted, expected)
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	actual := RenderBlocks(Notification{
+		Title:     "Tada68",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Reason:    "followed /u/bob",
+	})
+
+	expected := []slack.Block{
+		slack.Section("Tada68\n_(followed /u/bob)_"),
+		slack.Actions(
+			slack.LinkButton("web", "Web", "https://example.com/post"),
+			slack.LinkButton("app", "App", "https://git.io/vhZZN#/r/mechmarket/abc"),
+		),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+	// Slack buttons only link to http, so Apollo is left out
+	actual = RenderBlocks(Notification{
+		Title:     "Tada68",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Reason:    "followed /u/bob",
+		App:       AppApollo,
+	})
+	expected = []slack.Block{
+		slack.Section("Tada68\n_(followed /u/bob)_"),
+		slack.Actions(slack.LinkButton("web", "Web", "https://example.com/post")),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+}
+
+func TestSlackValidate(t *testing.T) {
+	obj := NewSlack(&fakeSlack{})
+	for target, valid := range map[string]bool{
+		"C0123ABCD": true,
+		"G0123ABCD": true,
+		"U0123ABCD": false,
+		"D0123ABCD": false,
+		"#general":  false,
+		"c0123abcd": false,
+		"C01":       false,
+	} {
+		err := obj.Validate(target)
+		if (err == nil) != valid {
+			t.Errorf("Expected %s valid to be %t, got %+v", target, valid, err)
+		}
+	}
+}
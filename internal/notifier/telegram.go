@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+)
+
+// maxCaptionLength is the longest caption Telegram allows on a photo
+const maxCaptionLength = 1024
+
+// TelegramHandler delivers notifications as Telegram messages
+type TelegramHandler struct {
+	chat chatter.Interface
+}
+
+// NewTelegram creates a notifier sharing the bot's Telegram connection
+func NewTelegram(chat chatter.Interface) *TelegramHandler {
+	return &TelegramHandler{chat: chat}
+}
+
+// Validate checks the target is a chat ID
+func (t *TelegramHandler) Validate(target string) error {
+	_, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("not a chat ID: %s", target)
+	}
+	return nil
+}
+
+// Notify sends the notification to a chat ID
+func (t *TelegramHandler) Notify(target string, n Notification) error {
+	chatID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("not a chat ID: %s", target)
+	}
+
+	message := RenderHTML(n)
+	if n.Rich && n.Image != "" && utf8.RuneCountInString(message) <= maxCaptionLength {
+		// Fall back to text when Telegram can't use the image
+		if err = t.chat.SendPhoto(chatID, n.Image, message); err == nil {
+			return nil
+		}
+	}
+	return t.chat.SendMessage(chatID, message)
+}
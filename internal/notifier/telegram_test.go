@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stjohnjohnson/reddit-watcher/internal/chatter"
+)
+
+func TestTelegramValidate(t *testing.T) {
+	obj := NewTelegram(nil)
+
+	if err := obj.Validate("-100123"); err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if err := obj.Validate("bob"); err == nil {
+		t.Errorf("Expected an error for a username")
+	}
+}
+
+// fakeChat records the messages and photos sent through it
+type fakeChat struct {
+	chatter.Interface
+	sent   []string
+	photos bool
+}
+
+func (f *fakeChat) SendMessage(chatID int64, message string) error {
+	f.sent = append(f.sent, fmt.Sprintf("msg/%d/%s", chatID, message))
+	return nil
+}
+
+func (f *fakeChat) SendPhoto(chatID int64, photoURL, caption string) error {
+	if !f.photos {
+		return fmt.Errorf("Unable to send: Bad Request: wrong file identifier/HTTP URL specified")
+	}
+	f.sent = append(f.sent, fmt.Sprintf("photo/%d/%s/%s", chatID, photoURL, caption))
+	return nil
+}
+
+func TestTelegramNotify(t *testing.T) {
+	chat := &fakeChat{photos: true}
+	obj := NewTelegram(chat)
+	n := Notification{Title: "Tada68", URL: "https://example.com/post", Reason: "matched selling tada68", Image: "https://i.imgur.com/AbCdE.jpg"}
+
+	obj.Notify("1", n)
+	n.Rich = true
+	obj.Notify("2", n)
+	chat.photos = false
+	obj.Notify("3", n)
+
+	message := RenderHTML(n)
+	expected := []string{
+		"msg/1/" + RenderHTML(Notification{Title: "Tada68", URL: "https://example.com/post", Reason: "matched selling tada68"}),
+		"photo/2/https://i.imgur.com/AbCdE.jpg/" + message,
+		"msg/3/" + message,
+	}
+	if !reflect.DeepEqual(chat.sent, expected) {
+		t.Errorf("Expected %q to equal %q", chat.sent, expected)
+	}
+}
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Styles that notifications can be rendered in
+const (
+	// StyleCompact is the title, author and links on one line
+	StyleCompact = "compact"
+	// StyleDetailed adds the price and the start of the post
+	StyleDetailed = "detailed"
+	// StyleLink is just the title, linking to the post
+	StyleLink = "link"
+)
+
+// Styles are the names of every style, the default first
+var Styles = []string{StyleCompact, StyleDetailed, StyleLink}
+
+// Apps that posts can be opened in
+const (
+	AppNarwhal = "narwhal"
+	AppApollo  = "apollo"
+	AppOld     = "old"
+	AppNew     = "new"
+)
+
+// Apps are the names of every app, the default first
+var Apps = []string{AppNarwhal, AppApollo, AppOld, AppNew}
+
+// app is how to link to a post in an app
+type app struct {
+	// URL is formatted with the permalink of the post
+	URL string
+	// Label is the text of the link
+	Label string
+}
+
+var apps = map[string]app{
+	// Narwhal links go through a redirect since chat apps only link to https
+	// Apollo has no redirect, so buttons that need https leave it out (see HasWebApp)
+	AppNarwhal: {URL: "https://git.io/vhZZN#%s", Label: "app"},
+	AppApollo:  {URL: "apollo://reddit.com%s", Label: "apollo"},
+	AppOld:     {URL: "https://old.reddit.com%s", Label: "old"},
+	AppNew:     {URL: "https://www.reddit.com%s", Label: "reddit"},
+}
+
+// htmlTemplates and textTemplates define a template for each style, and are empty
+// until LoadTemplates reads them from disk
+var (
+	htmlTemplates = htmltemplate.New("html")
+	textTemplates = texttemplate.New("text")
+)
+
+// LoadTemplates reads the styles from notification.html and notification.txt in a directory
+func LoadTemplates(dir string) error {
+	h, err := htmltemplate.ParseFiles(filepath.Join(dir, "notification.html"))
+	if err != nil {
+		return fmt.Errorf("load templates failed: %v", err)
+	}
+	t, err := texttemplate.ParseFiles(filepath.Join(dir, "notification.txt"))
+	if err != nil {
+		return fmt.Errorf("load templates failed: %v", err)
+	}
+
+	htmlTemplates, textTemplates = h, t
+	return nil
+}
+
+// view is what the templates are given, with the title already marked up
+type view struct {
+	Notification
+	Title    interface{}
+	Byline   string
+	AppURL   interface{}
+	AppLabel string
+}
+
+// AppURL is the link to the post in the chosen app
+func (n Notification) AppURL() string {
+	a, ok := apps[n.App]
+	if !ok {
+		a = apps[AppNarwhal]
+	}
+	return fmt.Sprintf(a.URL, n.Permalink)
+}
+
+// HasWebApp checks if the link to the post in the chosen app is https,
+// which Slack requires of button links
+func (n Notification) HasWebApp() bool {
+	return strings.HasPrefix(n.AppURL(), "https://")
+}
+
+// AppLabel is the text of the link to the post in the chosen app
+func (n Notification) AppLabel() string {
+	a, ok := apps[n.App]
+	if !ok {
+		a = apps[AppNarwhal]
+	}
+	return a.Label
+}
+
+// byline describes the author of the post
+func byline(n Notification) string {
+	if n.Author == "" {
+		return ""
+	}
+	if n.HasTrades {
+		return fmt.Sprintf(" by /u/%s (%d trades)", n.Author, n.Trades)
+	}
+	return fmt.Sprintf(" by /u/%s", n.Author)
+}
+
+// templateName returns the template for the chosen style
+func templateName(n Notification) string {
+	for _, s := range Styles {
+		if n.Style == s {
+			return s
+		}
+	}
+	return StyleCompact
+}
+
+// RenderHTML formats a notification in its style using Telegram's HTML markup
+func RenderHTML(n Notification) string {
+	var out bytes.Buffer
+	err := htmlTemplates.ExecuteTemplate(&out, templateName(n), view{
+		Notification: n,
+		Title:        htmltemplate.HTML(highlight(n, html.EscapeString, "<b>", "</b>")),
+		Byline:       byline(n),
+		// The app URL is built here, and may not be http
+		AppURL:   htmltemplate.URL(n.AppURL()),
+		AppLabel: n.AppLabel(),
+	})
+	if err != nil {
+		return html.EscapeString(n.Title)
+	}
+	return out.String()
+}
+
+// RenderText formats a notification in its style as plain text
+func RenderText(n Notification) string {
+	var out bytes.Buffer
+	err := textTemplates.ExecuteTemplate(&out, templateName(n), view{
+		Notification: n,
+		Title:        n.Title,
+		Byline:       byline(n),
+		AppURL:       n.AppURL(),
+		AppLabel:     n.AppLabel(),
+	})
+	if err != nil {
+		return n.Title
+	}
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	err := LoadTemplates("../../templates")
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRenderHTML(t *testing.T) {
+	n := Notification{
+		Title:     "[US-CA] [H] Tada68 & <GMK> [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob_<3",
+		Trades:    12,
+		HasTrades: true,
+		Highlight: regexp.MustCompile("(?i)(tada68)"),
+		Reason:    "matched selling tada68 <3",
+	}
+
+	actual := RenderHTML(n)
+	expected := `[US-CA] [H] <b>Tada68</b> &amp; &lt;GMK&gt; [W] PayPal by /u/bob_&lt;3 (12 trades) [<a href="https://example.com/post">web</a>] [<a href="https://git.io/vhZZN#/r/mechmarket/abc">app</a>] <i>(matched selling tada68 &lt;3)</i>`
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	n.Author = ""
+	n.Highlight = nil
+	actual = RenderHTML(n)
+	expected = `[US-CA] [H] Tada68 &amp; &lt;GMK&gt; [W] PayPal [<a href="https://example.com/post">web</a>] [<a href="https://git.io/vhZZN#/r/mechmarket/abc">app</a>] <i>(matched selling tada68 &lt;3)</i>`
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	n := Notification{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob",
+		Highlight: regexp.MustCompile("(?i)(tada68)"),
+		Reason:    "matched selling tada68",
+		Price:     "$100",
+		Excerpt:   "Tada68 with a case & cable",
+	}
+
+	for _, test := range []struct {
+		style, app, html, text string
+	}{
+		{
+			StyleDetailed, AppOld,
+			"[US-CA] [H] <b>Tada68</b> [W] PayPal by /u/bob\nPrice: <b>$100</b>\n<i>Tada68 with a case &amp; cable</i>\n[<a href=\"https://example.com/post\">web</a>] [<a href=\"https://old.reddit.com/r/mechmarket/abc\">old</a>] <i>(matched selling tada68)</i>",
+			"[US-CA] [H] Tada68 [W] PayPal by /u/bob\nPrice: $100\nTada68 with a case & cable\nweb: https://example.com/post\nold: https://old.reddit.com/r/mechmarket/abc\n(matched selling tada68)",
+		},
+		{
+			StyleLink, AppApollo,
+			`<a href="apollo://reddit.com/r/mechmarket/abc">[US-CA] [H] <b>Tada68</b> [W] PayPal</a>`,
+			"[US-CA] [H] Tada68 [W] PayPal\napollo://reddit.com/r/mechmarket/abc",
+		},
+		{
+			"unknown", AppNew,
+			`[US-CA] [H] <b>Tada68</b> [W] PayPal by /u/bob [<a href="https://example.com/post">web</a>] [<a href="https://www.reddit.com/r/mechmarket/abc">reddit</a>] <i>(matched selling tada68)</i>`,
+			"[US-CA] [H] Tada68 [W] PayPal by /u/bob\nweb: https://example.com/post\nreddit: https://www.reddit.com/r/mechmarket/abc\n(matched selling tada68)",
+		},
+	} {
+		n.Style = test.style
+		n.App = test.app
+		if actual := RenderHTML(n); actual != test.html {
+			t.Errorf("Expected %q to equal %q", actual, test.html)
+		}
+		if actual := RenderText(n); actual != test.text {
+			t.Errorf("Expected %q to equal %q", actual, test.text)
+		}
+	}
+}
+
+func TestRenderRich(t *testing.T) {
+	n := Notification{Title: "Tada68", URL: "https://example.com/post", Reason: "matched selling tada68", Excerpt: "Tada68 with a case", Rich: true}
+
+	expected := `Tada68 [<a href="https://example.com/post">web</a>] [<a href="https://git.io/vhZZN#">app</a>] <i>(matched selling tada68)</i>` + "\n<i>Tada68 with a case</i>"
+	if actual := RenderHTML(n); actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+	expected = "Tada68\nweb: https://example.com/post\napp: https://git.io/vhZZN#\n(matched selling tada68)\nTada68 with a case"
+	if actual := RenderText(n); actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
@@ -0,0 +1,255 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// webhookAttempts is how many times a delivery is tried before it's dead-lettered
+const webhookAttempts = 3
+
+// webhookPayload is the JSON document posted for each match
+type webhookPayload struct {
+	ID       string      `json:"id"`
+	Event    string      `json:"event"`
+	SentAt   time.Time   `json:"sent_at"`
+	UserID   int64       `json:"user_id"`
+	Type     string      `json:"type"`
+	Keywords []string    `json:"keywords"`
+	Reason   string      `json:"reason"`
+	Post     webhookPost `json:"post"`
+}
+
+// webhookPost is the post a payload is about
+type webhookPost struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Permalink string `json:"permalink"`
+	AppURL    string `json:"app_url"`
+	Author    string `json:"author"`
+	Trades    *int   `json:"trades"`
+	Region    string `json:"region"`
+	Have      string `json:"have"`
+	Want      string `json:"want"`
+}
+
+// webhookDelivery is a payload waiting to be posted
+type webhookDelivery struct {
+	target string
+	body   []byte
+}
+
+// deadLetter is a line of the dead-letter log, kept so the payload can be replayed
+type deadLetter struct {
+	Time     time.Time       `json:"time"`
+	Target   string          `json:"target"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// WebhookHandler delivers notifications as signed JSON posted to a URL
+// Deliveries are queued and retried in the background so a slow endpoint
+// doesn't hold up everyone else's matches
+type WebhookHandler struct {
+	secret     string
+	global     string
+	deadLetter string
+	client     *http.Client
+	trusted    *http.Client
+	backoff    time.Duration
+	queue      chan webhookDelivery
+	now        func() time.Time
+	logger     *log.Logger
+}
+
+// NewWebhook creates a notifier signing payloads with a key derived from the secret
+// The global URL, if any, is signed with the secret itself, and failed
+// deliveries are appended to the dead-letter log
+func NewWebhook(secret, global, deadLetter string) *WebhookHandler {
+	w := &WebhookHandler{
+		secret:     secret,
+		global:     global,
+		deadLetter: deadLetter,
+		client:     publicClient(),
+		trusted:    &http.Client{Timeout: 10 * time.Second},
+		backoff:    time.Second,
+		queue:      make(chan webhookDelivery, 100),
+		now:        time.Now,
+		logger:     log.New(os.Stderr, "[HOOK]  ", log.LstdFlags),
+	}
+	go w.work()
+	return w
+}
+
+// Validate checks the target is a public https URL other than the global one
+func (w *WebhookHandler) Validate(target string) error {
+	if w.global != "" && target == w.global {
+		return fmt.Errorf("that URL already gets every match")
+	}
+	_, err := parsePublicURL(target)
+	return err
+}
+
+// SigningKey returns the key payloads sent to a user's URL are signed with
+// It's derived from the secret, so it's safe to show to the user
+func (w *WebhookHandler) SigningKey(target string) string {
+	mac := hmac.New(sha256.New, []byte(w.secret))
+	mac.Write([]byte(target))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// key returns the key a payload is signed with, the global URL uses the secret itself
+func (w *WebhookHandler) key(target string) string {
+	if target == w.global {
+		return w.secret
+	}
+	return w.SigningKey(target)
+}
+
+// Notify queues the notification to be posted to a URL
+func (w *WebhookHandler) Notify(target string, n Notification) error {
+	body, err := json.Marshal(w.payload(n))
+	if err != nil {
+		return fmt.Errorf("Unable to encode payload: %v", err)
+	}
+
+	select {
+	case w.queue <- webhookDelivery{target: target, body: body}:
+		return nil
+	default:
+		w.bury(target, body, 0, "queue is full")
+		return fmt.Errorf("Unable to send: queue is full")
+	}
+}
+
+// payload describes a notification as JSON
+func (w *WebhookHandler) payload(n Notification) webhookPayload {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	keywords := n.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+	var trades *int
+	if n.HasTrades {
+		trades = &n.Trades
+	}
+
+	return webhookPayload{
+		ID:       hex.EncodeToString(id),
+		Event:    "match",
+		SentAt:   w.now().UTC(),
+		UserID:   n.UserID,
+		Type:     n.Type,
+		Keywords: keywords,
+		Reason:   n.Reason,
+		Post: webhookPost{
+			Title:     n.Title,
+			URL:       n.URL,
+			Permalink: n.Permalink,
+			AppURL:    n.AppURL(),
+			Author:    n.Author,
+			Trades:    trades,
+			Region:    n.Region,
+			Have:      n.Have,
+			Want:      n.Want,
+		},
+	}
+}
+
+// work delivers queued payloads one at a time
+func (w *WebhookHandler) work() {
+	for d := range w.queue {
+		w.deliver(d.target, d.body)
+	}
+}
+
+// deliver posts a payload, retrying server errors, and dead-letters it if it never arrives
+func (w *WebhookHandler) deliver(target string, body []byte) error {
+	var err error
+	var retry bool
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(w.backoff * time.Duration(1<<uint(attempt-2)))
+		}
+
+		retry, err = w.post(target, body)
+		if err == nil {
+			return nil
+		}
+		w.logger.Printf("Attempt %d to %s failed: %v", attempt, target, err)
+		if !retry {
+			w.bury(target, body, attempt, err.Error())
+			return err
+		}
+	}
+
+	w.bury(target, body, webhookAttempts, err.Error())
+	return err
+}
+
+// post sends a signed payload once, saying whether a failure is worth retrying
+func (w *WebhookHandler) post(target string, body []byte) (bool, error) {
+	timestamp := fmt.Sprint(w.now().Unix())
+	mac := hmac.New(sha256.New, []byte(w.key(target)))
+	fmt.Fprintf(mac, "v1:%s:", timestamp)
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("Unable to send: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Watcher-Timestamp", timestamp)
+	req.Header.Set("X-Watcher-Signature", "v1="+hex.EncodeToString(mac.Sum(nil)))
+
+	// The global URL is set by whoever runs the bot, so it may be on their own network
+	client := w.client
+	if target == w.global {
+		client = w.trusted
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("Unable to send: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("Unable to send: %s", resp.Status)
+	}
+	return false, nil
+}
+
+// bury appends a payload that couldn't be delivered to the dead-letter log
+func (w *WebhookHandler) bury(target string, body []byte, attempts int, reason string) {
+	line, err := json.Marshal(deadLetter{
+		Time:     w.now().UTC(),
+		Target:   target,
+		Attempts: attempts,
+		Error:    reason,
+		Payload:  body,
+	})
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(w.deadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.Write(append(line, '\n'))
+			f.Close()
+		}
+	}
+	if err != nil {
+		w.logger.Printf("Unable to dead-letter payload for %s: %v", target, err)
+	}
+}
@@ -0,0 +1,209 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testWebhook(deadLetter string) *WebhookHandler {
+	os.Remove(deadLetter)
+	obj := NewWebhook("secret", "https://hooks.example.com/all", deadLetter)
+	obj.backoff = 0
+	obj.client = &http.Client{}
+	obj.now = func() time.Time { return time.Unix(1500000000, 0) }
+	obj.logger = log.New(ioutil.Discard, "", 0)
+	return obj
+}
+
+func TestWebhookNotify(t *testing.T) {
+	obj := testWebhook("/tmp/webhook-notify.jsonl")
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mac := hmac.New(sha256.New, []byte(obj.SigningKey(requestURL(r))))
+		fmt.Fprintf(mac, "v1:%s:%s", r.Header.Get("X-Watcher-Timestamp"), body)
+		if r.Header.Get("X-Watcher-Signature") != "v1="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Expected a valid signature, got %s", r.Header.Get("X-Watcher-Signature"))
+		}
+		if r.Header.Get("X-Watcher-Timestamp") != "1500000000" {
+			t.Errorf("Expected a timestamp, got %s", r.Header.Get("X-Watcher-Timestamp"))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	err := obj.Notify(server.URL+"/hook", Notification{
+		Title:     "[US-CA] [H] Tada68 [W] PayPal",
+		URL:       "https://example.com/post",
+		Permalink: "/r/mechmarket/abc",
+		Author:    "bob",
+		Trades:    3,
+		HasTrades: true,
+		Reason:    "matched selling tada68",
+		Type:      "selling",
+		Keywords:  []string{"tada68"},
+		Region:    "US",
+		Have:      "Tada68",
+		Want:      "PayPal",
+		UserID:    1,
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+
+	var actual webhookPayload
+	json.Unmarshal(<-bodies, &actual)
+	if len(actual.ID) != 32 {
+		t.Errorf("Expected a delivery ID, got %q", actual.ID)
+	}
+	actual.ID = ""
+
+	trades := 3
+	expected := webhookPayload{
+		Event:    "match",
+		SentAt:   time.Unix(1500000000, 0).UTC(),
+		UserID:   1,
+		Type:     "selling",
+		Keywords: []string{"tada68"},
+		Reason:   "matched selling tada68",
+		Post: webhookPost{
+			Title:     "[US-CA] [H] Tada68 [W] PayPal",
+			URL:       "https://example.com/post",
+			Permalink: "/r/mechmarket/abc",
+			AppURL:    "https://git.io/vhZZN#/r/mechmarket/abc",
+			Author:    "bob",
+			Trades:    &trades,
+			Region:    "US",
+			Have:      "Tada68",
+			Want:      "PayPal",
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+}
+
+// requestURL returns the URL a test request was sent to
+func requestURL(r *http.Request) string {
+	return fmt.Sprintf("http://%s%s", r.Host, r.URL.Path)
+}
+
+func TestWebhookRetry(t *testing.T) {
+	obj := testWebhook("/tmp/webhook-retry.jsonl")
+	var actual []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actual = append(actual, r.URL.Path)
+		switch {
+		case r.URL.Path == "/flaky" && len(actual) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/flaky":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	err := obj.deliver(server.URL+"/flaky", []byte(`{"id":"1"}`))
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	err = obj.deliver(server.URL+"/down", []byte(`{"id":"2"}`))
+	if err == nil || err.Error() != "Unable to send: 503 Service Unavailable" {
+		t.Errorf("Expected an unavailable error, got %+v", err)
+	}
+	err = obj.deliver(server.URL+"/missing", []byte(`{"id":"3"}`))
+	if err == nil || err.Error() != "Unable to send: 404 Not Found" {
+		t.Errorf("Expected a not found error, got %+v", err)
+	}
+
+	expected := []string{"/flaky", "/flaky", "/down", "/down", "/down", "/missing"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	contents, _ := ioutil.ReadFile("/tmp/webhook-retry.jsonl")
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	expectedLines := []string{
+		fmt.Sprintf(`{"time":"2017-07-14T02:40:00Z","target":"%s/down","attempts":3,"error":"Unable to send: 503 Service Unavailable","payload":{"id":"2"}}`, server.URL),
+		fmt.Sprintf(`{"time":"2017-07-14T02:40:00Z","target":"%s/missing","attempts":1,"error":"Unable to send: 404 Not Found","payload":{"id":"3"}}`, server.URL),
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("Expected %q to equal %q", lines, expectedLines)
+	}
+}
+
+func TestWebhookSigningKey(t *testing.T) {
+	obj := testWebhook("/tmp/webhook-key.jsonl")
+
+	if key := obj.key("https://hooks.example.com/all"); key != "secret" {
+		t.Errorf("Expected the global webhook to use the secret, got %s", key)
+	}
+	if key := obj.SigningKey("https://hooks.example.com/all"); key == "secret" {
+		t.Errorf("Expected the secret to never be shown, got %s", key)
+	}
+	key := obj.SigningKey("https://example.com/hook")
+	if len(key) != 64 || key != obj.SigningKey("https://example.com/hook") || key == obj.SigningKey("https://example.com/other") {
+		t.Errorf("Expected a stable key for each URL, got %s", key)
+	}
+}
+
+func TestWebhookPublicOnly(t *testing.T) {
+	obj := testWebhook("/tmp/webhook-public.jsonl")
+	obj.client = publicClient()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	obj.global = server.URL + "/all"
+
+	_, err := obj.post(server.URL+"/hook", []byte("{}"))
+	if err == nil || !strings.Contains(err.Error(), "not a public address") {
+		t.Errorf("Expected a local address to be refused, got %+v", err)
+	}
+	_, err = obj.post(obj.global, []byte("{}"))
+	if err != nil {
+		t.Errorf("Expected the global webhook to be trusted, got %+v", err)
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	obj := testWebhook("/tmp/webhook-validate.jsonl")
+	for target, valid := range map[string]bool{
+		"https://example.com/hook":       true,
+		"https://203.0.113.7:8443/hook":  true,
+		"https://hooks.example.com/all":  false,
+		"http://example.com/hook":        false,
+		"https://localhost/hook":         false,
+		"https://127.0.0.1/hook":         false,
+		"https://[::1]/hook":             false,
+		"https://169.254.169.254/latest": false,
+		"https://10.0.0.5/hook":          false,
+		"https://172.20.1.1/hook":        false,
+		"https://192.168.1.10/hook":      false,
+		"https://[fd00::1]/hook":         false,
+		"not a url":                      false,
+	} {
+		err := obj.Validate(target)
+		if (err == nil) != valid {
+			t.Errorf("Expected %s valid to be %t, got %+v", target, valid, err)
+		}
+	}
+}
@@ -29,7 +29,7 @@ type Channel chan *reddit.Post
 
 // Post receives an update from Reddit and forwards it to the Channel
 func (r *Handler) Post(p *reddit.Post) error {
-	r.logger.Printf("Received post: %v", p.URL)
+	r.logger.Printf("Received post: %v by %v (%v)", p.URL, p.Author, p.AuthorFlairText)
 	r.channel <- p
 
 	return nil
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/matryer/persist"
+)
+
+// Job is something that should happen to a user's subscriptions at a given time
+type Job struct {
+	Kind    string
+	UserID  int64
+	Type    string
+	Keyword string
+	// At is when the job is due, a zero time is never due
+	At time.Time
+}
+
+// Handler keeps track of jobs and fires them when they are due
+type Handler struct {
+	jobs     []Job
+	path     string
+	interval time.Duration
+	channel  Channel
+	lock     sync.Mutex
+	// fired are the due jobs sent to the channel that aren't done yet
+	fired []Job
+}
+
+// Interface is the scheduler public functions
+type Interface interface {
+	Start() (Channel, error)
+	Add(Job) error
+	Find(Job) (Job, bool)
+	Remove(Job) error
+	Done(Job) error
+}
+
+// Channel is a channel of jobs that are due
+type Channel chan Job
+
+// sameJob checks if two jobs are for the same thing, ignoring when they are due
+func sameJob(a, b Job) bool {
+	return a.Kind == b.Kind && a.UserID == b.UserID && a.Type == b.Type && a.Keyword == b.Keyword
+}
+
+// exactJob checks if two jobs are for the same thing at the same time
+func exactJob(a, b Job) bool {
+	return sameJob(a, b) && a.At.Equal(b.At)
+}
+
+// Start checks for due jobs in the background and returns a channel to listen for them
+// Jobs that became due while the bot was stopped fire right away, and jobs stay
+// saved until they are marked Done so they fire again if the bot stops first
+func (s *Handler) Start() (Channel, error) {
+	go func() {
+		for {
+			for _, job := range s.due(time.Now()) {
+				s.channel <- job
+			}
+			time.Sleep(s.interval)
+		}
+	}()
+
+	return s.channel, nil
+}
+
+// Add schedules a job, replacing any existing job for the same thing
+func (s *Handler) Add(job Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jobs = append(s.remove(job), job)
+	return s.save()
+}
+
+// Find returns the scheduled job for the same thing
+func (s *Handler) Find(job Job) (Job, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, j := range s.jobs {
+		if sameJob(j, job) {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+// Remove cancels the scheduled job for the same thing
+func (s *Handler) Remove(job Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jobs = s.remove(job)
+	return s.save()
+}
+
+// Done removes a job that was handled, unless it was replaced since it fired
+func (s *Handler) Done(job Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	fired := []Job{}
+	for _, j := range s.fired {
+		if !exactJob(j, job) {
+			fired = append(fired, j)
+		}
+	}
+	s.fired = fired
+
+	jobs := []Job{}
+	for _, j := range s.jobs {
+		if !exactJob(j, job) {
+			jobs = append(jobs, j)
+		}
+	}
+	if len(jobs) == len(s.jobs) {
+		return nil
+	}
+
+	s.jobs = jobs
+	return s.save()
+}
+
+// remove returns the jobs without the one for the same thing
+func (s *Handler) remove(job Job) []Job {
+	jobs := []Job{}
+	for _, j := range s.jobs {
+		if !sameJob(j, job) {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// due returns the jobs that are due at the given time and haven't fired yet
+func (s *Handler) due(now time.Time) []Job {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	due := []Job{}
+	for _, j := range s.jobs {
+		if j.At.IsZero() || j.At.After(now) || s.hasFired(j) {
+			continue
+		}
+		due = append(due, j)
+		s.fired = append(s.fired, j)
+	}
+	return due
+}
+
+// hasFired checks if a job was already sent to the channel
+func (s *Handler) hasFired(job Job) bool {
+	for _, j := range s.fired {
+		if exactJob(j, job) {
+			return true
+		}
+	}
+	return false
+}
+
+// save persists the jobs to disk
+func (s *Handler) save() error {
+	err := persist.Save(fmt.Sprintf("%s.json", s.path), s.jobs)
+	if err != nil {
+		return fmt.Errorf("save jobs failed: %v", err)
+	}
+
+	return nil
+}
+
+// 30m, 12h, 3d, 2w
+var durationRex = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseDuration reads a duration in minutes, hours, days, or weeks (e.g. 3d)
+func ParseDuration(s string) (time.Duration, error) {
+	m := durationRex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	count, err := strconv.Atoi(m[1])
+	if err != nil || count == 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(count) * durationUnits[m[2]], nil
+}
+
+// Load recovers the scheduled jobs from disk
+func Load(path string) (*Handler, error) {
+	var jobs []Job
+
+	err := persist.Load(fmt.Sprintf("%s.json", path), &jobs)
+	if err != nil {
+		jobs = []Job{}
+	}
+
+	return &Handler{
+		jobs:     jobs,
+		path:     path,
+		interval: time.Minute,
+		channel:  make(Channel),
+	}, err
+}
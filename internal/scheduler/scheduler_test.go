@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	obj, _ := Load("/tmp/jobs-find")
+	obj.jobs = []Job{}
+	at := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	_, ok := obj.Find(Job{Kind: "pause", UserID: 1})
+	if ok {
+		t.Errorf("Expected no job")
+	}
+
+	err := obj.Add(Job{Kind: "pause", UserID: 1, At: at})
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	obj.Add(Job{Kind: "pause", UserID: 1, At: at.Add(time.Hour)})
+	obj.Add(Job{Kind: "snooze", UserID: 1, Type: "selling", Keyword: "tada68", At: at})
+
+	job, ok := obj.Find(Job{Kind: "pause", UserID: 1})
+	if !ok || !job.At.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected replaced job, got %+v", job)
+	}
+
+	obj.Remove(Job{Kind: "pause", UserID: 1})
+	if _, ok = obj.Find(Job{Kind: "pause", UserID: 1}); ok {
+		t.Errorf("Expected job to be removed")
+	}
+	if _, ok = obj.Find(Job{Kind: "snooze", UserID: 1, Type: "selling", Keyword: "tada68"}); !ok {
+		t.Errorf("Expected other job to be kept")
+	}
+}
+
+func TestDue(t *testing.T) {
+	obj, _ := Load("/tmp/jobs-due")
+	obj.jobs = []Job{}
+	at := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	obj.Add(Job{Kind: "pause", UserID: 1})
+	obj.Add(Job{Kind: "pause", UserID: 2, At: at})
+	obj.Add(Job{Kind: "pause", UserID: 3, At: at.Add(time.Hour)})
+
+	expected := []Job{{Kind: "pause", UserID: 2, At: at}}
+	if due := obj.due(at); !reflect.DeepEqual(due, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, due)
+	}
+	if due := obj.due(at); len(due) != 0 {
+		t.Errorf("Expected job to only be due once, got %+v", due)
+	}
+
+	// Survives a restart until it is done
+	obj, err := Load("/tmp/jobs-due")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if due := obj.due(at); !reflect.DeepEqual(due, expected) {
+		t.Errorf("Expected %+v after a restart, got %+v", expected, due)
+	}
+	obj.Done(Job{Kind: "pause", UserID: 2, At: at})
+
+	obj, _ = Load("/tmp/jobs-due")
+	expected = []Job{{Kind: "pause", UserID: 3, At: at.Add(time.Hour)}}
+	if due := obj.due(at.Add(24 * time.Hour)); !reflect.DeepEqual(due, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, due)
+	}
+}
+
+func TestDoneReplaced(t *testing.T) {
+	obj, _ := Load("/tmp/jobs-done")
+	obj.jobs = []Job{}
+	at := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	obj.Add(Job{Kind: "pause", UserID: 1, At: at})
+	fired := obj.due(at)
+	obj.Add(Job{Kind: "pause", UserID: 1, At: at.Add(time.Hour)})
+
+	err := obj.Done(fired[0])
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if job, ok := obj.Find(Job{Kind: "pause", UserID: 1}); !ok || !job.At.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected the new job to be kept, got %+v", job)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	totalTests := []struct {
+		in  string
+		out time.Duration
+		err bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"3d", 72 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"0d", 0, true},
+		{"3y", 0, true},
+		{"d", 0, true},
+	}
+
+	for _, tt := range totalTests {
+		out, err := ParseDuration(tt.in)
+
+		if out != tt.out || (err != nil) != tt.err {
+			t.Errorf("Expected %q to be %v/%v, got %v/%v", tt.in, tt.out, tt.err, out, err)
+		}
+	}
+}
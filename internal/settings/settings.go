@@ -0,0 +1,77 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/matryer/persist"
+)
+
+// Handler keeps the preferences of each chat
+type Handler struct {
+	values map[int64]map[string]string
+	path   string
+}
+
+// Interface is the settings public functions
+type Interface interface {
+	Get(int64, string) string
+	Set(int64, string, string) error
+	All(int64) map[string]string
+}
+
+// Get returns a setting for a given chat ID, empty when it isn't set
+func (s *Handler) Get(id int64, key string) string {
+	return s.values[id][key]
+}
+
+// Set changes a setting for a given chat ID, an empty value removes it
+func (s *Handler) Set(id int64, key, value string) error {
+	if value == "" {
+		delete(s.values[id], key)
+		if len(s.values[id]) == 0 {
+			delete(s.values, id)
+		}
+		return s.save()
+	}
+
+	if _, ok := s.values[id]; !ok {
+		s.values[id] = make(map[string]string)
+	}
+	s.values[id][key] = value
+
+	return s.save()
+}
+
+// All returns a copy of every setting for a given chat ID
+func (s *Handler) All(id int64) map[string]string {
+	values := make(map[string]string)
+	for key, value := range s.values[id] {
+		values[key] = value
+	}
+	return values
+}
+
+// save persists the settings to disk
+func (s *Handler) save() error {
+	err := persist.Save(fmt.Sprintf("%s.json", s.path), s.values)
+	if err != nil {
+		return fmt.Errorf("save settings failed: %v", err)
+	}
+
+	return nil
+}
+
+// Load recovers the settings from disk
+func Load(path string) (*Handler, error) {
+	var values map[int64]map[string]string
+
+	err := persist.Load(fmt.Sprintf("%s.json", path), &values)
+	if err != nil || values == nil {
+		values = make(map[int64]map[string]string)
+	}
+
+	return &Handler{
+		values: values,
+		path:   path,
+	}, err
+}
@@ -0,0 +1,40 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	obj, _ := Load("/tmp/settings")
+	obj.values = make(map[int64]map[string]string)
+
+	if value := obj.Get(1, "color"); value != "" {
+		t.Errorf("Expected empty setting, got %q", value)
+	}
+
+	err := obj.Set(1, "color", "blue")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	obj.Set(1, "size", "large")
+	obj.Set(2, "color", "red")
+
+	if value := obj.Get(1, "color"); value != "blue" {
+		t.Errorf("Expected blue, got %q", value)
+	}
+
+	obj.Set(1, "size", "")
+	expected := map[string]string{"color": "blue"}
+	if actual := obj.All(1); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+
+	obj, err = Load("/tmp/settings")
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	if value := obj.Get(2, "color"); value != "red" {
+		t.Errorf("Expected settings to be saved, got %q", value)
+	}
+}
@@ -0,0 +1,79 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+)
+
+// message is the body of a chat.postMessage call or response URL
+type message struct {
+	Channel         string  `json:"channel,omitempty"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
+	ResponseType    string  `json:"response_type,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+}
+
+// Block is a Block Kit layout block
+type Block struct {
+	Type     string    `json:"type"`
+	Text     *Text     `json:"text,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Element is a Block Kit button
+type Element struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Value    string `json:"value,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Section creates a block of mrkdwn text
+func Section(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}
+}
+
+// Actions creates a block of buttons
+func Actions(buttons ...Element) Block {
+	return Block{Type: "actions", Elements: buttons}
+}
+
+// Button creates a button that sends its value back to the bot when pressed
+func Button(actionID, text, value string) Element {
+	return Element{Type: "button", Text: &Text{Type: "plain_text", Text: text}, ActionID: actionID, Value: value}
+}
+
+// LinkButton creates a button that opens a URL
+func LinkButton(actionID, text, url string) Element {
+	return Element{Type: "button", Text: &Text{Type: "plain_text", Text: text}, ActionID: actionID, URL: url}
+}
+
+var (
+	boldRex = regexp.MustCompile(`(?s)<b>(.*?)</b>`)
+	italRex = regexp.MustCompile(`(?s)<i>(.*?)</i>`)
+	codeRex = regexp.MustCompile(`(?s)<code>(.*?)</code>`)
+	linkRex = regexp.MustCompile(`(?s)<a href="([^"]*)">(.*?)</a>`)
+)
+
+// Mrkdwn converts the bot's Telegram HTML replies into Slack's mrkdwn
+// Both escape &, < and > the same way, so those are left alone
+func Mrkdwn(text string) string {
+	text = boldRex.ReplaceAllString(text, "*$1*")
+	text = italRex.ReplaceAllString(text, "_${1}_")
+	text = codeRex.ReplaceAllString(text, "`$1`")
+	text = linkRex.ReplaceAllString(text, "<$1|$2>")
+	return strings.NewReplacer("&#34;", `"`, "&quot;", `"`, "&#39;", "'").Replace(text)
+}
+
+// Escape makes plain text safe to use in mrkdwn
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
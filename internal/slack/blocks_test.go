@@ -0,0 +1,21 @@
+package slack
+
+import (
+	"testing"
+)
+
+func TestMrkdwn(t *testing.T) {
+	actual := Mrkdwn(`Okay, I'm watching <b>selling</b> &amp; <i>(12 hits)</i> on <a href="https://example.com">the &#34;web&#34;</a> with <code>/mm-link</code>`)
+	expected := "Okay, I'm watching *selling* &amp; _(12 hits)_ on <https://example.com|the \"web\"> with `/mm-link`"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	actual := Escape("<GMK> & Tada68")
+	expected := "&lt;GMK&gt; &amp; Tada68"
+	if actual != expected {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
@@ -0,0 +1,242 @@
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxRequestSize is the largest request accepted from Slack
+const maxRequestSize = 1 << 20
+
+// maxRequestAge is how old a signed request can be before it's treated as a replay
+const maxRequestAge = 5 * time.Minute
+
+// Event is a slash command or button press from a Slack user
+type Event struct {
+	Team string
+	User string
+	// Command is the slash command, empty for button presses
+	Command string
+	Text    string
+	// Action is the value of the pressed button
+	Action      string
+	ResponseURL string
+}
+
+// Account is the name of the Slack user across workspaces
+func (e Event) Account() string {
+	return fmt.Sprintf("%s/%s", e.Team, e.User)
+}
+
+// Channel is a channel of events from Slack
+type Channel chan Event
+
+// Handler is a Slack app, receiving commands over HTTP and replying through the Web API
+type Handler struct {
+	token   string
+	secret  string
+	api     string
+	client  *http.Client
+	channel Channel
+	now     func() time.Time
+	logger  *log.Logger
+}
+
+// Interface is the slack public functions
+type Interface interface {
+	Start() (Channel, error)
+	PostMessage(string, string, []Block) error
+	Respond(string, string, []Block, bool) error
+}
+
+// actionPayload is the part of an interaction payload the bot uses
+type actionPayload struct {
+	Type string `json:"type"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		Value string `json:"value"`
+	} `json:"actions"`
+}
+
+// Start returns the channel of Slack events
+// Requests are served by the bot's web server through ServeHTTP
+func (s *Handler) Start() (Channel, error) {
+	return s.channel, nil
+}
+
+// ServeHTTP accepts slash commands on /slack/commands and button presses on /slack/actions
+// Slack expects an answer within three seconds, so events are queued and replied to later
+func (s *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "unable to read request", http.StatusBadRequest)
+		return
+	}
+	if err = s.verify(r.Header, body); err != nil {
+		s.logger.Printf("Rejected request: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "unable to parse request", http.StatusBadRequest)
+		return
+	}
+
+	var event Event
+	switch r.URL.Path {
+	case "/slack/commands":
+		event = Event{
+			Team:        form.Get("team_id"),
+			User:        form.Get("user_id"),
+			Command:     form.Get("command"),
+			Text:        form.Get("text"),
+			ResponseURL: form.Get("response_url"),
+		}
+
+	case "/slack/actions":
+		var payload actionPayload
+		if json.Unmarshal([]byte(form.Get("payload")), &payload) != nil || len(payload.Actions) == 0 {
+			http.Error(w, "unable to parse payload", http.StatusBadRequest)
+			return
+		}
+		event = Event{
+			Team:        payload.Team.ID,
+			User:        payload.User.ID,
+			Action:      payload.Actions[0].Value,
+			ResponseURL: payload.ResponseURL,
+		}
+
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	select {
+	case s.channel <- event:
+		w.WriteHeader(http.StatusOK)
+	case <-time.After(2 * time.Second):
+		http.Error(w, "too busy", http.StatusServiceUnavailable)
+	}
+}
+
+// verify checks the request was signed by Slack with the app's signing secret
+func (s *Handler) verify(header http.Header, body []byte) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing timestamp")
+	}
+	age := s.now().Sub(time.Unix(seconds, 0))
+	if age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("stale timestamp %s", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// post sends a JSON body to Slack, checking both the status and the ok field
+func (s *Handler) post(target string, auth bool, body interface{}) error {
+	contents, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("Unable to encode message: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(contents))
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if auth {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unable to send: %s", resp.Status)
+	}
+
+	// Response URLs answer with plain text, the Web API with JSON
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&result) == nil && !result.OK && result.Error != "" {
+		return fmt.Errorf("Unable to send: %s", result.Error)
+	}
+	return nil
+}
+
+// PostMessage sends a message to a channel, or a direct message when given a user ID
+func (s *Handler) PostMessage(channel, text string, blocks []Block) error {
+	return s.post(s.api+"/chat.postMessage", true, message{
+		Channel: channel,
+		Text:    text,
+		Blocks:  blocks,
+	})
+}
+
+// Respond answers a command or button press only the user can see
+// Replace swaps out the message holding the pressed button
+func (s *Handler) Respond(responseURL, text string, blocks []Block, replace bool) error {
+	return s.post(responseURL, false, message{
+		Text:            text,
+		Blocks:          blocks,
+		ResponseType:    "ephemeral",
+		ReplaceOriginal: replace,
+	})
+}
+
+// New creates a Slack app given a bot token and signing secret
+func New(token, secret string) (*Handler, error) {
+	if token == "" || secret == "" {
+		return nil, fmt.Errorf("Unable to setup: token and signing secret are required")
+	}
+
+	return &Handler{
+		token:   token,
+		secret:  secret,
+		api:     "https://slack.com/api",
+		client:  &http.Client{Timeout: 10 * time.Second},
+		channel: make(Channel, 100),
+		now:     time.Now,
+		logger:  log.New(os.Stderr, "[SLACK] ", log.LstdFlags),
+	}, nil
+}
@@ -0,0 +1,159 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testHandler() *Handler {
+	obj, _ := New("xoxb-token", "secret")
+	obj.now = func() time.Time { return time.Unix(1500000000, 0) }
+	obj.logger = log.New(ioutil.Discard, "", 0)
+	return obj
+}
+
+func signedRequest(path, body, secret string, timestamp int64) *http.Request {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%d:%s", timestamp, body)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", fmt.Sprint(timestamp))
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestCommand(t *testing.T) {
+	obj := testHandler()
+	body := url.Values{
+		"team_id":      {"T1"},
+		"user_id":      {"U1"},
+		"command":      {"/mm-selling"},
+		"text":         {"tada68, tofu"},
+		"response_url": {"https://hooks.slack.com/commands/1"},
+	}.Encode()
+
+	w := httptest.NewRecorder()
+	obj.ServeHTTP(w, signedRequest("/slack/commands", body, "secret", 1500000000))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+	expected := Event{Team: "T1", User: "U1", Command: "/mm-selling", Text: "tada68, tofu", ResponseURL: "https://hooks.slack.com/commands/1"}
+	if actual := <-obj.channel; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+	if expected.Account() != "T1/U1" {
+		t.Errorf("Expected T1/U1, got %s", expected.Account())
+	}
+}
+
+func TestAction(t *testing.T) {
+	obj := testHandler()
+	payload := `{"type":"block_actions","team":{"id":"T1"},"user":{"id":"U1"},"response_url":"https://hooks.slack.com/actions/1","actions":[{"action_id":"button-0","value":"clear all"}]}`
+	body := url.Values{"payload": {payload}}.Encode()
+
+	w := httptest.NewRecorder()
+	obj.ServeHTTP(w, signedRequest("/slack/actions", body, "secret", 1500000000))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+	expected := Event{Team: "T1", User: "U1", Action: "clear all", ResponseURL: "https://hooks.slack.com/actions/1"}
+	if actual := <-obj.channel; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+}
+
+func TestRejected(t *testing.T) {
+	obj := testHandler()
+	body := url.Values{"command": {"/mm-help"}}.Encode()
+
+	for _, test := range []struct {
+		req  *http.Request
+		code int
+	}{
+		{signedRequest("/slack/commands", body, "wrong", 1500000000), http.StatusUnauthorized},
+		{signedRequest("/slack/commands", body, "secret", 1500000000-600), http.StatusUnauthorized},
+		{httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body)), http.StatusUnauthorized},
+		{httptest.NewRequest(http.MethodGet, "/slack/commands", nil), http.StatusMethodNotAllowed},
+		{signedRequest("/slack/other", body, "secret", 1500000000), http.StatusNotFound},
+		{signedRequest("/slack/actions", "payload=nope", "secret", 1500000000), http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		obj.ServeHTTP(w, test.req)
+		if w.Code != test.code {
+			t.Errorf("Expected %d for %s, got %d", test.code, test.req.URL.Path, w.Code)
+		}
+	}
+
+	if len(obj.channel) != 0 {
+		t.Errorf("Expected no events, got %d", len(obj.channel))
+	}
+}
+
+func TestPostMessage(t *testing.T) {
+	var actual []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		actual = append(actual, fmt.Sprintf("%s %s %s", r.URL.Path, r.Header.Get("Authorization"), body))
+
+		switch r.URL.Path {
+		case "/api/chat.postMessage":
+			var msg message
+			json.Unmarshal(body, &msg)
+			if msg.Channel == "C404" {
+				fmt.Fprint(w, `{"ok":false,"error":"channel_not_found"}`)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true}`)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer server.Close()
+
+	obj := testHandler()
+	obj.api = server.URL + "/api"
+
+	err := obj.PostMessage("U1", "hello", []Block{Section("*hello*")})
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	err = obj.PostMessage("C404", "hello", nil)
+	if err == nil || err.Error() != "Unable to send: channel_not_found" {
+		t.Errorf("Expected channel_not_found, got %+v", err)
+	}
+	err = obj.Respond(server.URL+"/respond", "done", nil, true)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+
+	expected := []string{
+		`/api/chat.postMessage Bearer xoxb-token {"channel":"U1","text":"hello","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"*hello*"}}]}`,
+		`/api/chat.postMessage Bearer xoxb-token {"channel":"C404","text":"hello"}`,
+		`/respond  {"text":"done","response_type":"ephemeral","replace_original":true}`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %q to equal %q", actual, expected)
+	}
+}
+
+func TestNewMissingSecret(t *testing.T) {
+	_, err := New("xoxb-token", "")
+	if err == nil {
+		t.Errorf("Expected an error without a signing secret")
+	}
+}
@@ -0,0 +1,103 @@
+package unparsed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/matryer/persist"
+)
+
+// Entry is a post title that could not be parsed
+type Entry struct {
+	Title string
+	Flair string
+	Time  time.Time
+}
+
+// corpus is the representation saved to disk
+type corpus struct {
+	Total   int64
+	Failed  int64
+	Entries []Entry
+}
+
+// Handler keeps a bounded list of the most recent unparsable titles
+type Handler struct {
+	corpus corpus
+	limit  int
+	path   string
+}
+
+// Interface is the unparsed public functions
+type Interface interface {
+	Record(string, string, bool) error
+	Recent(int) []Entry
+	Rate() (int64, int64)
+}
+
+// Record keeps track of a title, saving it to the corpus if it was not parsed
+// The counters are saved on every call so the rate survives a restart
+func (u *Handler) Record(title, flair string, parsed bool) error {
+	u.corpus.Total++
+	if parsed {
+		return u.save()
+	}
+
+	u.corpus.Failed++
+	u.corpus.Entries = append(u.corpus.Entries, Entry{
+		Title: title,
+		Flair: flair,
+		Time:  time.Now(),
+	})
+	if u.limit > 0 && len(u.corpus.Entries) > u.limit {
+		u.corpus.Entries = u.corpus.Entries[len(u.corpus.Entries)-u.limit:]
+	}
+
+	return u.save()
+}
+
+// Recent returns up to count of the newest entries, newest first
+// A count of zero returns every entry
+func (u *Handler) Recent(count int) []Entry {
+	entries := u.corpus.Entries
+	if count <= 0 || count > len(entries) {
+		count = len(entries)
+	}
+
+	recent := make([]Entry, count)
+	for i := range recent {
+		recent[i] = entries[len(entries)-1-i]
+	}
+	return recent
+}
+
+// Rate returns the number of unparsable titles and the total number of titles seen
+func (u *Handler) Rate() (int64, int64) {
+	return u.corpus.Failed, u.corpus.Total
+}
+
+// save persists the corpus to disk
+func (u *Handler) save() error {
+	err := persist.Save(fmt.Sprintf("%s.json", u.path), u.corpus)
+	if err != nil {
+		return fmt.Errorf("save corpus failed: %v", err)
+	}
+
+	return nil
+}
+
+// Load recovers the corpus from disk, keeping at most limit entries
+func Load(path string, limit int) (*Handler, error) {
+	var c corpus
+
+	err := persist.Load(fmt.Sprintf("%s.json", path), &c)
+	if err != nil {
+		c = corpus{}
+	}
+
+	return &Handler{
+		corpus: c,
+		limit:  limit,
+		path:   path,
+	}, err
+}
@@ -0,0 +1,72 @@
+package unparsed
+
+import (
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	obj, _ := Load("/tmp/unparsed", 2)
+	obj.corpus = corpus{}
+
+	obj.Record("[US-CA] [H] Tada68 [W] PayPal", "Selling", true)
+	err := obj.Record("first", "", false)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+	obj.Record("second", "Meta", false)
+	obj.Record("third", "", false)
+
+	failed, total := obj.Rate()
+	if failed != 3 || total != 4 {
+		t.Errorf("Expected 3 of 4, got %d of %d", failed, total)
+	}
+
+	entries := obj.Recent(0)
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Title != "third" || entries[1].Title != "second" {
+		t.Errorf("Expected newest first, got %+v", entries)
+	}
+	if entries[1].Flair != "Meta" {
+		t.Errorf("Expected flair to be kept, got %+v", entries[1])
+	}
+
+	entries = obj.Recent(1)
+	if len(entries) != 1 || entries[0].Title != "third" {
+		t.Errorf("Expected only the newest entry, got %+v", entries)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	obj, _ := Load("/tmp/unparsed-load", 10)
+	obj.Record("first", "", false)
+
+	obj, err := Load("/tmp/unparsed-load", 10)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+
+	entries := obj.Recent(0)
+	if len(entries) == 0 || entries[0].Title != "first" {
+		t.Errorf("Expected saved entry, got %+v", entries)
+	}
+}
+
+func TestLoadRate(t *testing.T) {
+	obj, _ := Load("/tmp/unparsed-rate", 10)
+	obj.corpus = corpus{}
+	obj.Record("[US-CA] [H] Tada68 [W] PayPal", "Selling", true)
+	obj.Record("first", "", false)
+	obj.Record("[US-CA] [H] PayPal [W] Tada68", "Buying", true)
+
+	obj, err := Load("/tmp/unparsed-rate", 10)
+	if err != nil {
+		t.Errorf("Expected no error, got %+v", err)
+	}
+
+	failed, total := obj.Rate()
+	if failed != 1 || total != 3 {
+		t.Errorf("Expected 1 of 3, got %d of %d", failed, total)
+	}
+}
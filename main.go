@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/stjohnjohnson/reddit-watcher/internal/bot"
 )
@@ -20,9 +22,59 @@ func main() {
 
 	token := flag.String("token", "INVALID", "Bot Token for Telegram")
 	configPath := flag.String("config", "/config", "Location of user data")
+	synonymsPath := flag.String("synonyms", "/synonyms.json", "Location of the synonym dictionary")
+	templatesPath := flag.String("templates", "/templates", "Location of the notification templates")
+	admins := flag.String("admins", "", "Comma separated list of admin chat IDs")
+	slackToken := flag.String("slack-token", "", "Bot Token for Slack, leave empty to turn off Slack")
+	slackSecret := flag.String("slack-secret", "", "Signing Secret for Slack")
+	listen := flag.String("listen", ":8080", "Address to listen on for Slack requests and unsubscribe links")
+	smtpAddr := flag.String("smtp-addr", "", "SMTP server (host:port) to send email through, leave empty to turn off email")
+	smtpUsername := flag.String("smtp-username", "", "Username for the SMTP server")
+	smtpPassword := flag.String("smtp-password", "", "Password for the SMTP server")
+	smtpFrom := flag.String("smtp-from", "", "Address to send email from")
+	emailSecret := flag.String("email-secret", "", "Secret used to sign unsubscribe links")
+	publicURL := flag.String("public-url", "", "URL the listen address can be reached at, used in unsubscribe links")
+	webhookSecret := flag.String("webhook-secret", "", "Secret used to sign webhook payloads, leave empty to turn off webhooks")
+	webhookURL := flag.String("webhook-url", "", "URL to send every match to as a webhook")
+	matrixHomeserver := flag.String("matrix-homeserver", "", "URL of the Matrix homeserver, leave empty to turn off Matrix")
+	matrixUser := flag.String("matrix-user", "", "Matrix user to login as")
+	matrixPassword := flag.String("matrix-password", "", "Password for the Matrix user")
 	flag.Parse()
 
-	bot, err := bot.New(*token, *configPath, version)
+	adminIDs := []int64{}
+	for _, field := range strings.Split(*admins, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid admin chat ID %q: %v", field, err)
+		}
+		adminIDs = append(adminIDs, id)
+	}
+
+	bot, err := bot.New(bot.Config{
+		Token:            *token,
+		ConfigDir:        *configPath,
+		Synonyms:         *synonymsPath,
+		Templates:        *templatesPath,
+		Admins:           adminIDs,
+		Version:          version,
+		SlackToken:       *slackToken,
+		SlackSecret:      *slackSecret,
+		Listen:           *listen,
+		SMTPAddr:         *smtpAddr,
+		SMTPUsername:     *smtpUsername,
+		SMTPPassword:     *smtpPassword,
+		SMTPFrom:         *smtpFrom,
+		EmailSecret:      *emailSecret,
+		PublicURL:        *publicURL,
+		WebhookSecret:    *webhookSecret,
+		WebhookURL:       *webhookURL,
+		MatrixHomeserver: *matrixHomeserver,
+		MatrixUser:       *matrixUser,
+		MatrixPassword:   *matrixPassword,
+	})
 	if err != nil {
 		log.Fatalf("Unable to start bot: %v", err)
 	}
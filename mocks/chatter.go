@@ -4,8 +4,18 @@ import "github.com/stjohnjohnson/reddit-watcher/internal/chatter"
 
 // Chatter is mocked
 type Chatter struct {
-	MockStart       func() (chatter.Channel, error)
-	MockSendMessage func(int64, string) error
+	MockStart          func() (chatter.Channel, error)
+	MockUsername       func() string
+	MockIsChatAdmin    func(int64, int) (bool, error)
+	MockSendMessage    func(int64, string) error
+	MockSendButtons    func(int64, string, []chatter.Button) error
+	MockSendKeyboard   func(int64, string, [][]chatter.Button) error
+	MockEditMessage    func(int64, int, string) error
+	MockEditKeyboard   func(int64, int, string, [][]chatter.Button) error
+	MockAnswerCallback func(string, string) error
+	MockSendDocument   func(int64, string, []byte, string) error
+	MockSendPhoto      func(int64, string, string) error
+	MockGetFile        func(string) ([]byte, error)
 }
 
 // GetAll is mocked
@@ -21,6 +31,22 @@ func (m *Chatter) Start() (chatter.Channel, error) {
 	return nil, nil
 }
 
+// Username is mocked
+func (m *Chatter) Username() string {
+	if m.MockUsername != nil {
+		return m.MockUsername()
+	}
+	return ""
+}
+
+// IsChatAdmin is mocked
+func (m *Chatter) IsChatAdmin(i int64, u int) (bool, error) {
+	if m.MockIsChatAdmin != nil {
+		return m.MockIsChatAdmin(i, u)
+	}
+	return false, nil
+}
+
 // SendMessage is mocked
 func (m *Chatter) SendMessage(i int64, s string) error {
 	if m.MockSendMessage != nil {
@@ -29,6 +55,70 @@ func (m *Chatter) SendMessage(i int64, s string) error {
 	return nil
 }
 
+// SendButtons is mocked
+func (m *Chatter) SendButtons(i int64, s string, b []chatter.Button) error {
+	if m.MockSendButtons != nil {
+		return m.MockSendButtons(i, s, b)
+	}
+	return nil
+}
+
+// SendKeyboard is mocked
+func (m *Chatter) SendKeyboard(i int64, s string, r [][]chatter.Button) error {
+	if m.MockSendKeyboard != nil {
+		return m.MockSendKeyboard(i, s, r)
+	}
+	return nil
+}
+
+// EditKeyboard is mocked
+func (m *Chatter) EditKeyboard(i int64, n int, s string, r [][]chatter.Button) error {
+	if m.MockEditKeyboard != nil {
+		return m.MockEditKeyboard(i, n, s, r)
+	}
+	return nil
+}
+
+// EditMessage is mocked
+func (m *Chatter) EditMessage(i int64, n int, s string) error {
+	if m.MockEditMessage != nil {
+		return m.MockEditMessage(i, n, s)
+	}
+	return nil
+}
+
+// AnswerCallback is mocked
+func (m *Chatter) AnswerCallback(id, s string) error {
+	if m.MockAnswerCallback != nil {
+		return m.MockAnswerCallback(id, s)
+	}
+	return nil
+}
+
+// SendDocument is mocked
+func (m *Chatter) SendDocument(i int64, n string, c []byte, s string) error {
+	if m.MockSendDocument != nil {
+		return m.MockSendDocument(i, n, c, s)
+	}
+	return nil
+}
+
+// SendPhoto is mocked
+func (m *Chatter) SendPhoto(i int64, u, c string) error {
+	if m.MockSendPhoto != nil {
+		return m.MockSendPhoto(i, u, c)
+	}
+	return nil
+}
+
+// GetFile is mocked
+func (m *Chatter) GetFile(s string) ([]byte, error) {
+	if m.MockGetFile != nil {
+		return m.MockGetFile(s)
+	}
+	return nil, nil
+}
+
 // Save is mocked
 func (m *Chatter) Save() error {
 	return nil
@@ -7,11 +7,13 @@ type Data struct {
 	MockGet          func(int64) data.Keywords
 	MockGetByKeyword func(string) []int64
 	MockGetKeywords  func() []string
+	MockGetUsers     func() []int64
 	MockSync         func()
 	MockAdd          func(int64, string) error
 	MockExists       func(int64, string) bool
 	MockRemove       func(int64, string) error
 	MockIncrement    func(int64, string) error
+	MockDetails      func(int64, string) data.Details
 }
 
 // Get is mocked
@@ -38,6 +40,14 @@ func (m *Data) GetKeywords() []string {
 	return nil
 }
 
+// GetUsers is mocked
+func (m *Data) GetUsers() []int64 {
+	if m.MockGetUsers != nil {
+		return m.MockGetUsers()
+	}
+	return nil
+}
+
 // Sync is mocked
 func (m *Data) Sync() {
 	if m.MockSync != nil {
@@ -76,3 +86,11 @@ func (m *Data) Increment(i int64, s string) error {
 	}
 	return nil
 }
+
+// Details is mocked
+func (m *Data) Details(i int64, s string) data.Details {
+	if m.MockDetails != nil {
+		return m.MockDetails(i, s)
+	}
+	return data.Details{}
+}
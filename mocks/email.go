@@ -0,0 +1,34 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/email"
+
+// Email is mocked
+type Email struct {
+	MockStart          func() (email.Channel, error)
+	MockSend           func(string, string, string, string) error
+	MockUnsubscribeURL func(string) string
+}
+
+// Start is mocked
+func (m *Email) Start() (email.Channel, error) {
+	if m.MockStart != nil {
+		return m.MockStart()
+	}
+	return nil, nil
+}
+
+// Send is mocked
+func (m *Email) Send(to, subject, text, html string) error {
+	if m.MockSend != nil {
+		return m.MockSend(to, subject, text, html)
+	}
+	return nil
+}
+
+// UnsubscribeURL is mocked
+func (m *Email) UnsubscribeURL(s string) string {
+	if m.MockUnsubscribeURL != nil {
+		return m.MockUnsubscribeURL(s)
+	}
+	return ""
+}
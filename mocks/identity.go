@@ -0,0 +1,50 @@
+package mocks
+
+// Identity is mocked
+type Identity struct {
+	MockResolve func(string, string) (int64, error)
+	MockClaim   func(string, string, int64) (int64, error)
+	MockLookup  func(int64) (string, string, bool)
+	MockNewCode func(int64) (string, error)
+	MockRedeem  func(string, int64) (int64, bool, error)
+}
+
+// Resolve is mocked
+func (m *Identity) Resolve(t, a string) (int64, error) {
+	if m.MockResolve != nil {
+		return m.MockResolve(t, a)
+	}
+	return 0, nil
+}
+
+// Claim is mocked, keeping the ID by default
+func (m *Identity) Claim(t, a string, i int64) (int64, error) {
+	if m.MockClaim != nil {
+		return m.MockClaim(t, a, i)
+	}
+	return i, nil
+}
+
+// Lookup is mocked
+func (m *Identity) Lookup(i int64) (string, string, bool) {
+	if m.MockLookup != nil {
+		return m.MockLookup(i)
+	}
+	return "", "", false
+}
+
+// NewCode is mocked
+func (m *Identity) NewCode(i int64) (string, error) {
+	if m.MockNewCode != nil {
+		return m.MockNewCode(i)
+	}
+	return "", nil
+}
+
+// Redeem is mocked
+func (m *Identity) Redeem(c string, i int64) (int64, bool, error) {
+	if m.MockRedeem != nil {
+		return m.MockRedeem(c, i)
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/matrix"
+
+// Matrix is mocked
+type Matrix struct {
+	MockStart       func() (matrix.Channel, error)
+	MockSendMessage func(string, string, string) error
+	MockIsRoomAdmin func(string, string) (bool, error)
+}
+
+// Start is mocked
+func (m *Matrix) Start() (matrix.Channel, error) {
+	if m.MockStart != nil {
+		return m.MockStart()
+	}
+	return nil, nil
+}
+
+// SendMessage is mocked
+func (m *Matrix) SendMessage(r, t, h string) error {
+	if m.MockSendMessage != nil {
+		return m.MockSendMessage(r, t, h)
+	}
+	return nil
+}
+
+// IsRoomAdmin is mocked
+func (m *Matrix) IsRoomAdmin(r, u string) (bool, error) {
+	if m.MockIsRoomAdmin != nil {
+		return m.MockIsRoomAdmin(r, u)
+	}
+	return false, nil
+}
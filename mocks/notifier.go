@@ -0,0 +1,53 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/notifier"
+
+// Notifier is mocked
+type Notifier struct {
+	MockValidate func(string) error
+	MockNotify   func(string, notifier.Notification) error
+}
+
+// Validate is mocked
+func (m *Notifier) Validate(s string) error {
+	if m.MockValidate != nil {
+		return m.MockValidate(s)
+	}
+	return nil
+}
+
+// Notify is mocked
+func (m *Notifier) Notify(s string, n notifier.Notification) error {
+	if m.MockNotify != nil {
+		return m.MockNotify(s, n)
+	}
+	return nil
+}
+
+// Verifier is a mocked notifier that confirms targets
+type Verifier struct {
+	Notifier
+	MockVerify func(string, string) error
+}
+
+// Verify is mocked
+func (m *Verifier) Verify(t, c string) error {
+	if m.MockVerify != nil {
+		return m.MockVerify(t, c)
+	}
+	return nil
+}
+
+// Signer is a mocked notifier that signs what it sends
+type Signer struct {
+	Notifier
+	MockSigningKey func(string) string
+}
+
+// SigningKey is mocked
+func (m *Signer) SigningKey(t string) string {
+	if m.MockSigningKey != nil {
+		return m.MockSigningKey(t)
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/scheduler"
+
+// Scheduler is mocked
+type Scheduler struct {
+	MockStart  func() (scheduler.Channel, error)
+	MockAdd    func(scheduler.Job) error
+	MockFind   func(scheduler.Job) (scheduler.Job, bool)
+	MockRemove func(scheduler.Job) error
+	MockDone   func(scheduler.Job) error
+}
+
+// Start is mocked
+func (m *Scheduler) Start() (scheduler.Channel, error) {
+	if m.MockStart != nil {
+		return m.MockStart()
+	}
+	return nil, nil
+}
+
+// Add is mocked
+func (m *Scheduler) Add(j scheduler.Job) error {
+	if m.MockAdd != nil {
+		return m.MockAdd(j)
+	}
+	return nil
+}
+
+// Find is mocked
+func (m *Scheduler) Find(j scheduler.Job) (scheduler.Job, bool) {
+	if m.MockFind != nil {
+		return m.MockFind(j)
+	}
+	return scheduler.Job{}, false
+}
+
+// Remove is mocked
+func (m *Scheduler) Remove(j scheduler.Job) error {
+	if m.MockRemove != nil {
+		return m.MockRemove(j)
+	}
+	return nil
+}
+
+// Done is mocked
+func (m *Scheduler) Done(j scheduler.Job) error {
+	if m.MockDone != nil {
+		return m.MockDone(j)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package mocks
+
+// Settings is mocked
+type Settings struct {
+	MockGet func(int64, string) string
+	MockSet func(int64, string, string) error
+	MockAll func(int64) map[string]string
+}
+
+// Get is mocked
+func (m *Settings) Get(i int64, k string) string {
+	if m.MockGet != nil {
+		return m.MockGet(i, k)
+	}
+	return ""
+}
+
+// Set is mocked
+func (m *Settings) Set(i int64, k, v string) error {
+	if m.MockSet != nil {
+		return m.MockSet(i, k, v)
+	}
+	return nil
+}
+
+// All is mocked
+func (m *Settings) All(i int64) map[string]string {
+	if m.MockAll != nil {
+		return m.MockAll(i)
+	}
+	return nil
+}
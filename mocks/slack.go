@@ -0,0 +1,34 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/slack"
+
+// Slack is mocked
+type Slack struct {
+	MockStart       func() (slack.Channel, error)
+	MockPostMessage func(string, string, []slack.Block) error
+	MockRespond     func(string, string, []slack.Block, bool) error
+}
+
+// Start is mocked
+func (m *Slack) Start() (slack.Channel, error) {
+	if m.MockStart != nil {
+		return m.MockStart()
+	}
+	return nil, nil
+}
+
+// PostMessage is mocked
+func (m *Slack) PostMessage(c, t string, b []slack.Block) error {
+	if m.MockPostMessage != nil {
+		return m.MockPostMessage(c, t, b)
+	}
+	return nil
+}
+
+// Respond is mocked
+func (m *Slack) Respond(u, t string, b []slack.Block, r bool) error {
+	if m.MockRespond != nil {
+		return m.MockRespond(u, t, b, r)
+	}
+	return nil
+}
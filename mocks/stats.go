@@ -3,7 +3,7 @@ package mocks
 // Stats is mocked
 type Stats struct {
 	MockGetAll    func() map[string]string
-	MockIncrement func()
+	MockIncrement func(string)
 }
 
 // GetAll is mocked
@@ -15,8 +15,8 @@ func (s *Stats) GetAll() map[string]string {
 }
 
 // Increment is mocked
-func (s *Stats) Increment(string) {
+func (s *Stats) Increment(flag string) {
 	if s.MockIncrement != nil {
-		s.MockIncrement()
+		s.MockIncrement(flag)
 	}
 }
@@ -0,0 +1,34 @@
+package mocks
+
+import "github.com/stjohnjohnson/reddit-watcher/internal/unparsed"
+
+// Unparsed is mocked
+type Unparsed struct {
+	MockRecord func(string, string, bool) error
+	MockRecent func(int) []unparsed.Entry
+	MockRate   func() (int64, int64)
+}
+
+// Record is mocked
+func (m *Unparsed) Record(t, f string, p bool) error {
+	if m.MockRecord != nil {
+		return m.MockRecord(t, f, p)
+	}
+	return nil
+}
+
+// Recent is mocked
+func (m *Unparsed) Recent(i int) []unparsed.Entry {
+	if m.MockRecent != nil {
+		return m.MockRecent(i)
+	}
+	return nil
+}
+
+// Rate is mocked
+func (m *Unparsed) Rate() (int64, int64) {
+	if m.MockRate != nil {
+		return m.MockRate()
+	}
+	return 0, 0
+}